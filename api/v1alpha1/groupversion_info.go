@@ -25,13 +25,52 @@ import (
 )
 
 const (
-	SAFMachineKind = "SAFMachine"
-	SAFClusterKind = "SAFCluster"
+	SAFMachineKind         = "SAFMachine"
+	SAFClusterKind         = "SAFCluster"
+	SAFMachineTemplateKind = "SAFMachineTemplate"
+	SAFClusterTemplateKind = "SAFClusterTemplate"
+	SAFHostKind            = "SAFHost"
+
+	SAFMachinePoolKind         = "SAFMachinePool"
+	SAFMachinePoolTemplateKind = "SAFMachinePoolTemplate"
 )
 
 const (
 	SAFMachineFinalizer = "infrastructure.cluster.x-k8s.io/safmachine"
 	SAFClusterFinalizer = "infrastructure.cluster.x-k8s.io/safcluster"
+	SAFHostFinalizer    = "infrastructure.cluster.x-k8s.io/safhost"
+
+	SAFMachinePoolFinalizer = "infrastructure.cluster.x-k8s.io/safmachinepool"
+)
+
+const (
+	// SAFMachineNameLabel is set on jobs and their pods, that run for a SAFMachine.
+	SAFMachineNameLabel = "infrastructure.cluster.x-k8s.io/safmachine-name"
+
+	// SAFHostNameLabel is set on cleaning jobs and their pods, that run for a SAFHost.
+	SAFHostNameLabel = "infrastructure.cluster.x-k8s.io/safhost-name"
+
+	// SAFClusterNameLabel is set on jobs and their pods, that run for a SAFCluster.
+	SAFClusterNameLabel = "infrastructure.cluster.x-k8s.io/safcluster-name"
+
+	// SAFMachinePoolNameLabel is set on SAFMachines, that are instances of a SAFMachinePool.
+	SAFMachinePoolNameLabel = "infrastructure.cluster.x-k8s.io/safmachinepool-name"
+
+	// FailureDomainLabel places SAFHosts into the failure domain of SAFCluster, that has no hostSelector.
+	FailureDomainLabel = "infrastructure.cluster.x-k8s.io/failure-domain"
+)
+
+const (
+	// StatusAnnotation keeps status of SAFMachines, SAFClusters and SAFHosts, as clusterctl move doesn't move status.
+	// It is maintained by controllers and must not be edited.
+	StatusAnnotation = "infrastructure.cluster.x-k8s.io/status"
+)
+
+const (
+	// BootstrapVolumeName is the name of the volume with Machine's bootstrap data, that is added to jobs.
+	BootstrapVolumeName = "bootstrap"
+	// BootstrapMountPath is where the bootstrap data is mounted in job containers.
+	BootstrapMountPath = "/etc/bootstrap/"
 )
 
 var (
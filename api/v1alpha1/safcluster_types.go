@@ -18,39 +18,80 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 )
 
-// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
-// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
-
 // SAFClusterSpec defines the desired state of SAFCluster
 type SAFClusterSpec struct {
-	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
-	// The following markers will use OpenAPI v3 schema to validate the value
-	// More info: https://book.kubebuilder.io/reference/markers/crd-validation.html
+	// controlPlaneEndpoint represents the endpoint used to communicate with the control plane,
+	// e.g. a VIP or a DNS name of a load balancer in front of control plane hosts.
+	// The SAFCluster is provisioned, once it is set.
+	// +optional
+	ControlPlaneEndpoint capiv1beta2.APIEndpoint `json:"controlPlaneEndpoint,omitempty,omitzero"`
+
+	// provisionJob is the template of the job, that runs once per cluster before it is provisioned,
+	// e.g. to set up a load balancer VIP, DNS records or firewall rules.
+	// The job may report controlPlaneEndpoint in SAFClusterProvisionResult, if it is not set in the spec.
+	// +optional
+	ProvisionJob *JobTemplate `json:"provisionJob,omitempty"`
+
+	// deprovisionJob is the template of the job, that cleans up external resources of the cluster.
+	// SAFCluster is not deleted, until the job succeeds.
+	// +optional
+	DeprovisionJob *JobTemplate `json:"deprovisionJob,omitempty"`
+
+	// failureDomains are racks, sites or zones, that machines of the cluster are spread across.
+	// They are reported in status.failureDomains, so Cluster API places machines into them.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=100
+	FailureDomains []SAFFailureDomain `json:"failureDomains,omitempty"`
+}
+
+// SAFFailureDomain is a failure domain of the cluster. SAFMachines, placed into the failure domain by their Machine,
+// claim hosts of the failure domain and run their jobs on nodes of the failure domain.
+type SAFFailureDomain struct {
+	// name is the name of the failure domain.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	Name string `json:"name"`
+
+	// controlPlane determines if this failure domain is suitable for use by control plane machines.
+	// +optional
+	ControlPlane *bool `json:"controlPlane,omitempty"`
+
+	// attributes is a free form map of attributes, reported to Cluster API.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
 
-	// foo is an example field of SAFCluster. Edit safcluster_types.go to remove/update
+	// hostSelector selects SAFHosts of the failure domain, it narrows hostSelector of SAFMachines.
+	// Defaults to SAFHosts labeled with infrastructure.cluster.x-k8s.io/failure-domain=<name>.
 	// +optional
-	Foo *string `json:"foo,omitempty"`
+	HostSelector *metav1.LabelSelector `json:"hostSelector,omitempty"`
+
+	// jobNodeSelector is added to the node selector of jobs of SAFMachines in the failure domain,
+	// so they run on runners, that can reach hosts of the failure domain.
+	// +optional
+	JobNodeSelector map[string]string `json:"jobNodeSelector,omitempty"`
 }
 
 // SAFClusterStatus defines the observed state of SAFCluster.
 type SAFClusterStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// initialization provides observations of the SAFCluster initialization process.
+	// NOTE: Fields in this struct are part of the Cluster API contract and are used to orchestrate initial Cluster provisioning.
+	// +optional
+	Initialization SAFClusterInitializationStatus `json:"initialization,omitempty,omitzero"`
 
-	// For Kubernetes API conventions, see:
-	// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#typical-status-properties
+	// failureDomains is a list of failure domain objects synced from spec.failureDomains.
+	// NOTE: this field is part of the Cluster API contract, and it is used to orchestrate placement of Machines.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=100
+	FailureDomains []capiv1beta2.FailureDomain `json:"failureDomains,omitempty"`
 
 	// conditions represent the current state of the SAFCluster resource.
-	// Each condition has a unique type and reflects the status of a specific aspect of the resource.
-	//
-	// Standard condition types include:
-	// - "Available": the resource is fully functional
-	// - "Progressing": the resource is being created or updated
-	// - "Degraded": the resource failed to reach or maintain its desired state
-	//
 	// The status of each condition is one of True, False, or Unknown.
 	// +listType=map
 	// +listMapKey=type
@@ -58,6 +99,81 @@ type SAFClusterStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// SAFClusterProvisionResult is a JSON document, that cluster provision job containers may write
+// to their termination message file to report provisioned infrastructure back to the controller.
+// Fields of all succeeded containers are merged, empty fields are ignored.
+type SAFClusterProvisionResult struct {
+	// controlPlaneEndpoint is set to spec.controlPlaneEndpoint of the SAFCluster, unless it is already set.
+	// +optional
+	ControlPlaneEndpoint capiv1beta2.APIEndpoint `json:"controlPlaneEndpoint,omitempty,omitzero"`
+}
+
+// SAFClusterInitializationStatus provides observations of the SAFCluster initialization process.
+// +kubebuilder:validation:MinProperties=1
+type SAFClusterInitializationStatus struct {
+	// provisioned is true when the infrastructure of the cluster is ready and the control plane endpoint is known.
+	// NOTE: this field is part of the Cluster API contract, and it is used to orchestrate initial Cluster provisioning.
+	// +optional
+	Provisioned *bool `json:"provisioned,omitempty"`
+}
+
+// SAFCluster's Ready condition and corresponding reasons, that are mirrored by Cluster's InfrastructureReady condition.
+const (
+	// SAFClusterReadyCondition is true if the SAFCluster is provisioned and is not deleting.
+	SAFClusterReadyCondition = capiv1beta2.ReadyCondition
+
+	// SAFClusterReadyReason surfaces when the SAFCluster readiness criteria is met.
+	SAFClusterReadyReason = capiv1beta2.ReadyReason
+
+	// SAFClusterNotReadyReason surfaces when the SAFCluster readiness criteria is not met.
+	SAFClusterNotReadyReason = capiv1beta2.NotReadyReason
+
+	// SAFClusterReadyUnknownReason surfaces when at least one SAFCluster readiness criteria is unknown
+	// and no SAFCluster readiness criteria is not met.
+	SAFClusterReadyUnknownReason = capiv1beta2.ReadyUnknownReason
+)
+
+// SAFCluster's ControlPlaneEndpointAvailable condition and corresponding reasons.
+const (
+	// SAFClusterControlPlaneEndpointAvailableCondition is true if spec.controlPlaneEndpoint is set.
+	SAFClusterControlPlaneEndpointAvailableCondition = "ControlPlaneEndpointAvailable"
+
+	// SAFClusterControlPlaneEndpointAvailableReason surfaces when the control plane endpoint is set.
+	SAFClusterControlPlaneEndpointAvailableReason = "Available"
+
+	// SAFClusterWaitingForControlPlaneEndpointReason surfaces when the control plane endpoint is not set yet.
+	SAFClusterWaitingForControlPlaneEndpointReason = "WaitingForControlPlaneEndpoint"
+)
+
+// SAFCluster's Paused condition, it is reported with reasons from Cluster API.
+const (
+	// SAFClusterPausedCondition is true if the SAFCluster or its Cluster is paused.
+	SAFClusterPausedCondition = capiv1beta2.PausedCondition
+)
+
+// SAFCluster's ProvisionJobSucceeded and DeprovisionJobSucceeded conditions and corresponding reasons.
+// They are reported only for SAFCluster with the corresponding job template,
+// DeprovisionJobSucceeded is reported only for deleting SAFCluster.
+const (
+	// SAFClusterProvisionJobSucceededCondition is true if the provision job of the SAFCluster succeeded.
+	SAFClusterProvisionJobSucceededCondition = "ProvisionJobSucceeded"
+
+	// SAFClusterDeprovisionJobSucceededCondition is true if the deprovision job of the SAFCluster succeeded.
+	SAFClusterDeprovisionJobSucceededCondition = "DeprovisionJobSucceeded"
+
+	// SAFClusterJobNotCreatedReason surfaces when the job is not created yet.
+	SAFClusterJobNotCreatedReason = "JobNotCreated"
+
+	// SAFClusterJobRunningReason surfaces when the job is created and is not finished yet.
+	SAFClusterJobRunningReason = "JobRunning"
+
+	// SAFClusterJobFailedReason surfaces when the job failed.
+	SAFClusterJobFailedReason = "JobFailed"
+
+	// SAFClusterJobSucceededReason surfaces when the job succeeded.
+	SAFClusterJobSucceededReason = "JobSucceeded"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -78,6 +194,16 @@ type SAFCluster struct {
 	Status SAFClusterStatus `json:"status,omitempty,omitzero"`
 }
 
+// GetConditions returns the set of conditions for this object.
+func (c *SAFCluster) GetConditions() []metav1.Condition {
+	return c.Status.Conditions
+}
+
+// SetConditions sets conditions for an API object.
+func (c *SAFCluster) SetConditions(conditions []metav1.Condition) {
+	c.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // SAFClusterList contains a list of SAFCluster
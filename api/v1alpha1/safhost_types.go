@@ -0,0 +1,169 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SAFHostSpec defines the desired state of SAFHost
+type SAFHostSpec struct {
+	// connectionConfig describes how to reach the host. It is exposed to jobs of the SAFMachine,
+	// that claimed the host, SAFMachine's own connection config overrides it.
+	// +optional
+	ConnectionConfig map[string]string `json:"connectionConfig,omitempty,omitzero"`
+
+	// connectionConfigFrom exposes Secret data as connection config. Secrets are read from the namespace of the job,
+	// that uses them: the namespace of the SAFMachine, that claimed the host, or of the cleaning job.
+	// +optional
+	// +listType=atomic
+	ConnectionConfigFrom []ConnectionConfigSource `json:"connectionConfigFrom,omitempty"`
+
+	// ssh provisions the host over SSH, unless the SAFMachine, that claimed the host, defines its own provisioning.
+	// The key Secret is read from the namespace of the SAFMachine.
+	// +optional
+	SSH *SSHProvisioner `json:"ssh,omitempty"`
+
+	// cleaningJob is the template of the job, that wipes disks and resets the OS of the host,
+	// after the SAFMachine, that claimed it, is deprovisioned. The host is not claimed again, until the job succeeds.
+	// The job runs in the namespace of the SAFMachine.
+	// Connection config of the host is exposed to the job as env. Without it the host is available right after deprovisioning.
+	// +optional
+	CleaningJob *JobTemplate `json:"cleaningJob,omitempty"`
+
+	// capacity is the inventory of the host resources, e.g. cpu, memory, pods and ephemeral-storage
+	// of the node, that runs on the host. SAFMachineTemplates report it to cluster-autoscaler.
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+
+	// nodeInfo is the architecture and the operating system of the host.
+	// +optional
+	NodeInfo *NodeInfo `json:"nodeInfo,omitempty"`
+
+	// consumerRef is the SAFMachine, that claimed the host. It is set and cleared by the controller.
+	// +optional
+	ConsumerRef *corev1.ObjectReference `json:"consumerRef,omitempty"`
+}
+
+// SAFHostState is the state of the host in its lifecycle.
+// +kubebuilder:validation:Enum=Available;Provisioning;Provisioned;Deprovisioning;Cleaning;Error
+type SAFHostState string
+
+const (
+	// SAFHostAvailable means the host may be claimed by a SAFMachine.
+	SAFHostAvailable SAFHostState = "Available"
+	// SAFHostProvisioning means the host is claimed by the SAFMachine in consumerRef and is being provisioned.
+	SAFHostProvisioning SAFHostState = "Provisioning"
+	// SAFHostProvisioned means the host is provisioned for the SAFMachine in consumerRef.
+	SAFHostProvisioned SAFHostState = "Provisioned"
+	// SAFHostDeprovisioning means the SAFMachine in consumerRef is deleted and the host is being deprovisioned.
+	SAFHostDeprovisioning SAFHostState = "Deprovisioning"
+	// SAFHostCleaning means the host is released and the cleaning job runs.
+	SAFHostCleaning SAFHostState = "Cleaning"
+	// SAFHostError means the cleaning job failed. Deleting the failed job starts cleaning again.
+	SAFHostError SAFHostState = "Error"
+)
+
+// SAFHostStatus defines the observed state of SAFHost.
+type SAFHostStatus struct {
+	// state of the host in its lifecycle.
+	// +optional
+	State SAFHostState `json:"state,omitempty"`
+
+	// cleaningJobName is the name of the last cleaning job.
+	// +optional
+	CleaningJobName string `json:"cleaningJobName,omitempty"`
+
+	// cleaningJobNamespace is the namespace of the SAFMachine, that released the host, cleaning jobs run there.
+	// +optional
+	CleaningJobNamespace string `json:"cleaningJobNamespace,omitempty"`
+
+	// cleanings is the number of cleaning jobs, started for the host.
+	// +optional
+	Cleanings int32 `json:"cleanings,omitempty"`
+
+	// The status of each condition is one of True, False, or Unknown.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// SAFHost's Cleaned condition and corresponding reasons.
+const (
+	// SAFHostCleanedCondition is true if the last cleaning job of the host succeeded.
+	SAFHostCleanedCondition = "Cleaned"
+
+	// SAFHostCleanedReason surfaces when the cleaning job succeeded.
+	SAFHostCleanedReason = "Cleaned"
+
+	// SAFHostCleaningReason surfaces when the cleaning job is running.
+	SAFHostCleaningReason = "Cleaning"
+
+	// SAFHostCleaningFailedReason surfaces when the cleaning job failed.
+	SAFHostCleaningFailedReason = "CleaningFailed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:metadata:labels="clusterctl.cluster.x-k8s.io/move-hierarchy="
+
+// SAFHost is the Schema for the safhosts API.
+// It is a physical or pre-existing machine, that SAFMachines of any namespace claim by their hostSelector.
+// The host is not deleted, while it is claimed or cleaned.
+// SAFHosts don't belong to a Cluster, but clusterctl move moves them together with Clusters.
+// Secrets, referenced by SAFHosts and SAFMachines, are moved only with the clusterctl.cluster.x-k8s.io/move label.
+type SAFHost struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of SAFHost
+	// +required
+	Spec SAFHostSpec `json:"spec"`
+
+	// status defines the observed state of SAFHost
+	// +optional
+	Status SAFHostStatus `json:"status,omitempty,omitzero"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (h *SAFHost) GetConditions() []metav1.Condition {
+	return h.Status.Conditions
+}
+
+// SetConditions sets conditions for an API object.
+func (h *SAFHost) SetConditions(conditions []metav1.Condition) {
+	h.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// SAFHostList contains a list of SAFHost
+type SAFHostList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SAFHost `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SAFHost{}, &SAFHostList{})
+}
@@ -18,7 +18,10 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -26,19 +29,342 @@ import (
 
 // SAFMachineSpec defines the desired state of SAFMachine
 type SAFMachineSpec struct {
+	// providerID must match the provider ID as seen on the node object corresponding to this machine.
+	// Defaults to saf://<namespace>/<name> and is exposed to jobs as SAF_PROVIDER_ID env.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=512
+	ProviderID string `json:"providerID,omitempty"`
+
+	// connectionConfig describes how to reach the host. Every entry is exposed to job containers as env,
+	// named by the key in upper case with a prefix of the manager, e.g. host is exposed as SAF_HOST.
 	// +optional
 	ConnectionConfig map[string]string `json:"connectionConfig,omitempty,omitzero"`
 
-	ProvisionJob   JobTemplate `json:"provisionJob"`
-	DeprovisionJob JobTemplate `json:"deprovisionJob"`
+	// connectionConfigFrom exposes Secret data as connection config, e.g. passwords, tokens or ssh keys.
+	// Values are never copied out of the Secrets, jobs read them by secret key references.
+	// Entries override connectionConfig with the same name.
+	// +optional
+	// +listType=atomic
+	ConnectionConfigFrom []ConnectionConfigSource `json:"connectionConfigFrom,omitempty"`
+
+	// hostSelector selects cluster scoped SAFHosts. When it is set, the controller claims
+	// an available matching host before provisioning, exposes its connection settings to provisioning,
+	// and releases the host after deprovisioning.
+	// +optional
+	HostSelector *metav1.LabelSelector `json:"hostSelector,omitempty"`
+
+	// provisioner is the name of the backend, that provisions the host.
+	// Job backend runs provisionJob and deprovisionJob, or jobs generated for ssh.
+	// Noop backend does nothing and reports success at once, e.g. for hosts prepared out of band.
+	// Simulate backend runs no pods and reports success or failure after a delay, see simulate.
+	// Other backends may be registered in the manager.
+	// +kubebuilder:default=Job
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Provisioner string `json:"provisioner,omitempty"`
+
+	// provisionJob is the template of the job, that provisions the host.
+	// Required for Job provisioner, unless provisioning is done by ssh of the SAFMachine or its SAFHost.
+	// +optional
+	ProvisionJob JobTemplate `json:"provisionJob,omitempty,omitzero"`
+	// deprovisionJob is the template of the job, that cleans the host up, when the SAFMachine is deleted.
+	// Required for Job provisioner, unless provisioning is done by ssh of the SAFMachine or its SAFHost.
+	// +optional
+	DeprovisionJob JobTemplate `json:"deprovisionJob,omitempty,omitzero"`
+
+	// inspectionJob is the template of the job, that runs before provisioning and reports facts about the host,
+	// like its architecture, CPUs, memory, disks and NICs, see HostFacts. The facts are recorded in status.hostFacts
+	// and provisioning waits for them. Failed inspection is retried, when its job is deleted, so ttlSecondsAfterFinished
+	// is not defaulted for it. Supported by Job provisioner only.
+	// +optional
+	InspectionJob *JobTemplate `json:"inspectionJob,omitempty"`
+
+	// hostRequirements are checked against facts, reported by inspectionJob. Provisioning is blocked,
+	// until the host meets them. Requires inspectionJob.
+	// +optional
+	HostRequirements *HostRequirements `json:"hostRequirements,omitempty"`
+
+	// ssh provisions the host over SSH, with jobs generated by the controller.
+	// The bootstrap data is uploaded to the host and executed there, so no custom job image is required.
+	// +optional
+	SSH *SSHProvisioner `json:"ssh,omitempty"`
+
+	// retryPolicy defines how failed provisioning is retried.
+	// Without it a single provision job is created, that is not retried by the controller.
+	// +optional
+	RetryPolicy *ProvisionRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// simulate configures the Simulate provisioner, overriding defaults of the manager.
+	// +optional
+	Simulate *SimulateConfig `json:"simulate,omitempty"`
+
+	// provisionTimeout limits time from creation of the first provision job till successful provisioning.
+	// When it is exceeded, the provision job is deleted and the machine is marked as failed.
+	// Overrides the default of the manager, zero disables the timeout.
+	// +optional
+	ProvisionTimeout *metav1.Duration `json:"provisionTimeout,omitempty"`
+}
+
+const (
+	// JobProvisioner provisions hosts by jobs, it is the default.
+	JobProvisioner = "Job"
+	// NoopProvisioner does nothing and reports success at once.
+	NoopProvisioner = "Noop"
+	// SimulateProvisioner pretends to provision hosts, for testing Cluster API flows without real hosts.
+	SimulateProvisioner = "Simulate"
+)
+
+// SimulateConfig configures the Simulate provisioner. Provisioning finishes after the delay,
+// the host keeps the default provider ID, gets the SAFMachine's name as hostname and a fake internal IP.
+// A tainted Node with the provider ID is created in the workload cluster, its Ready condition is renewed
+// while the SAFMachine exists. Setting the condition to False makes the Node unhealthy for MachineHealthCheck.
+type SimulateConfig struct {
+	// delay is the duration of provisioning and deprovisioning.
+	// +optional
+	Delay *metav1.Duration `json:"delay,omitempty"`
+
+	// failurePercent is the chance of a provisioning attempt to fail, in percent.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	FailurePercent *int32 `json:"failurePercent,omitempty"`
+}
+
+// ProvisionRetryStrategy defines who retries failed provisioning.
+// +kubebuilder:validation:Enum=RecreateJob;JobBackoff
+type ProvisionRetryStrategy string
+
+const (
+	// RecreateJobRetryStrategy makes the controller create a new provision job for every attempt,
+	// waiting exponentially growing delay between attempts.
+	RecreateJobRetryStrategy ProvisionRetryStrategy = "RecreateJob"
+
+	// JobBackoffRetryStrategy makes the single provision job retry pods,
+	// its backoffLimit defaults to maxAttempts-1.
+	JobBackoffRetryStrategy ProvisionRetryStrategy = "JobBackoff"
+)
+
+// ProvisionRetryPolicy defines how failed provisioning is retried.
+type ProvisionRetryPolicy struct {
+	// maxAttempts is the number of provisioning attempts, including the first one.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// strategy defines who retries failed provisioning.
+	// +kubebuilder:default=RecreateJob
+	// +optional
+	Strategy ProvisionRetryStrategy `json:"strategy,omitempty"`
+
+	// initialDelay is the delay before the second attempt, it is doubled for every next attempt.
+	// Used by RecreateJob strategy only.
+	// +kubebuilder:default="10s"
+	// +optional
+	InitialDelay *metav1.Duration `json:"initialDelay,omitempty"`
+
+	// maxDelay limits the delay between attempts.
+	// Used by RecreateJob strategy only.
+	// +kubebuilder:default="5m"
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+}
+
+// ConnectionConfigSource exposes Secret data as connection config.
+// Exactly one of valueFrom and secretRef must be set.
+type ConnectionConfigSource struct {
+	// name of the connection config entry, it is required with valueFrom.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// valueFrom selects a key of a Secret in the SAFMachine's namespace as the value of the entry.
+	// +optional
+	ValueFrom *ConnectionConfigValueSource `json:"valueFrom,omitempty"`
+
+	// secretRef exposes every key of a Secret in the SAFMachine's namespace as an entry.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// ConnectionConfigValueSource is a source of a connection config value.
+type ConnectionConfigValueSource struct {
+	// secretKeyRef selects a key of a Secret.
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// SSHProvisioner provisions the host over SSH.
+type SSHProvisioner struct {
+	// host is the address of the host.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	Host string `json:"host"`
+
+	// port of the SSH server.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=22
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// user to log in as, it must be able to run sudo without password.
+	// +kubebuilder:default=root
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// keySecretRef selects the private key in a Secret in the SAFMachine's namespace.
+	KeySecretRef corev1.SecretKeySelector `json:"keySecretRef"`
+
+	// hostKey is the public key of the host, as in known_hosts, e.g. "ssh-ed25519 AAAA...".
+	// When it is empty, the host key is not checked.
+	// +optional
+	HostKey string `json:"hostKey,omitempty"`
+
+	// provisionCommand runs on the host after the bootstrap data is uploaded to /var/lib/saf/bootstrap-data.
+	// Defaults to running the bootstrap data with cloud-init.
+	// +optional
+	ProvisionCommand string `json:"provisionCommand,omitempty"`
+
+	// deprovisionCommand runs on the host, when the SAFMachine is deleted.
+	// Defaults to kubeadm reset.
+	// +optional
+	DeprovisionCommand string `json:"deprovisionCommand,omitempty"`
+
+	// image of the job, that runs ssh. Defaults to the image set for the manager.
+	// +optional
+	Image string `json:"image,omitempty"`
 }
 
 type JobTemplate struct {
 	Spec v1.JobSpec `json:"spec"`
 }
 
+// ProvisionResult is a JSON document, that provision job containers may write
+// to their termination message file to report provisioned host back to the controller.
+// Fields of all succeeded containers are merged, empty fields are ignored.
+type ProvisionResult struct {
+	// providerID of the host, overrides spec.providerID of the SAFMachine.
+	// +optional
+	ProviderID string `json:"providerID,omitempty"`
+
+	// hostname of the host, reported as Hostname address.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// addresses of the host.
+	// +optional
+	Addresses []capiv1beta2.MachineAddress `json:"addresses,omitempty"`
+
+	// failureDomain the host is placed in.
+	// +optional
+	FailureDomain string `json:"failureDomain,omitempty"`
+}
+
+// HostRequirements are minimal requirements to the host, checked before provisioning.
+type HostRequirements struct {
+	// architecture of the host, as reported by inspection, e.g. amd64 or arm64.
+	// +kubebuilder:validation:MaxLength=64
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// cpus is the minimal number of logical CPUs.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	CPUs *int32 `json:"cpus,omitempty"`
+
+	// memory is the minimal amount of memory.
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+
+	// diskSize is the minimal size of the largest disk.
+	// +optional
+	DiskSize *resource.Quantity `json:"diskSize,omitempty"`
+}
+
+// HostFacts is a JSON document, that inspection job containers may write to their termination message file
+// to report facts about the host back to the controller. Fields of all succeeded containers are merged,
+// empty fields are ignored.
+type HostFacts struct {
+	// architecture of the host, e.g. amd64 or arm64.
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// os is the operating system of the host, e.g. "Ubuntu 24.04".
+	// +optional
+	OS string `json:"os,omitempty"`
+
+	// cpus is the number of logical CPUs.
+	// +optional
+	CPUs int32 `json:"cpus,omitempty"`
+
+	// memory is the amount of memory.
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+
+	// disks of the host.
+	// +optional
+	// +listType=atomic
+	Disks []HostDisk `json:"disks,omitempty"`
+
+	// nics are network interfaces of the host.
+	// +optional
+	// +listType=atomic
+	NICs []HostNIC `json:"nics,omitempty"`
+}
+
+// HostDisk is a disk of the host.
+type HostDisk struct {
+	// name of the disk, e.g. sda.
+	Name string `json:"name"`
+
+	// size of the disk.
+	Size resource.Quantity `json:"size"`
+}
+
+// HostNIC is a network interface of the host.
+type HostNIC struct {
+	// name of the interface, e.g. eth0.
+	Name string `json:"name"`
+
+	// macAddress of the interface.
+	// +optional
+	MACAddress string `json:"macAddress,omitempty"`
+}
+
 // SAFMachineStatus defines the observed state of SAFMachine.
 type SAFMachineStatus struct {
+	// initialization provides observations of the SAFMachine initialization process.
+	// NOTE: Fields in this struct are part of the Cluster API contract and are used to orchestrate initial Machine provisioning.
+	// +optional
+	Initialization SAFMachineInitializationStatus `json:"initialization,omitempty,omitzero"`
+
+	// addresses contains the associated addresses for the machine.
+	// +optional
+	Addresses []capiv1beta2.MachineAddress `json:"addresses,omitempty"`
+
+	// nodeName is the name of the workload cluster Node, that runs on this machine.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// failureDomain is the unique identifier of the failure domain where this Machine has been placed in.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	FailureDomain string `json:"failureDomain,omitempty"`
+
+	// hostRef is the SAFHost, claimed by this machine.
+	// +optional
+	HostRef *corev1.LocalObjectReference `json:"hostRef,omitempty"`
+
+	// hostFacts are reported by the inspection job.
+	// +optional
+	HostFacts *HostFacts `json:"hostFacts,omitempty"`
+
+	// provisionAttempts records provision jobs, created for this machine.
+	// +optional
+	ProvisionAttempts []ProvisionAttempt `json:"provisionAttempts,omitempty"`
+
 	// The status of each condition is one of True, False, or Unknown.
 	// +listType=map
 	// +listMapKey=type
@@ -46,6 +372,182 @@ type SAFMachineStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// ProvisionAttemptResult is the result of a provisioning attempt.
+// +kubebuilder:validation:Enum=Running;Succeeded;Failed;TimedOut
+type ProvisionAttemptResult string
+
+const (
+	// ProvisionAttemptRunning means the provision job of the attempt is not finished yet.
+	ProvisionAttemptRunning ProvisionAttemptResult = "Running"
+
+	// ProvisionAttemptSucceeded means the provision job of the attempt succeeded.
+	ProvisionAttemptSucceeded ProvisionAttemptResult = "Succeeded"
+
+	// ProvisionAttemptFailed means the provision job of the attempt failed.
+	ProvisionAttemptFailed ProvisionAttemptResult = "Failed"
+
+	// ProvisionAttemptTimedOut means provisioning timeout exceeded during the attempt, so it was canceled.
+	ProvisionAttemptTimedOut ProvisionAttemptResult = "TimedOut"
+)
+
+// ProvisionAttempt records a provision job, created for the SAFMachine.
+type ProvisionAttempt struct {
+	// attempt is the number of the attempt, starting from 1.
+	Attempt int32 `json:"attempt"`
+
+	// jobName is the name of the provision job of the attempt.
+	JobName string `json:"jobName"`
+
+	// startTime is the time the provision job was created.
+	StartTime metav1.Time `json:"startTime"`
+
+	// completionTime is the time the provision job finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// result of the attempt.
+	Result ProvisionAttemptResult `json:"result"`
+
+	// message describes the failure of the attempt.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// SAFMachineInitializationStatus provides observations of the SAFMachine initialization process.
+// +kubebuilder:validation:MinProperties=1
+type SAFMachineInitializationStatus struct {
+	// provisioned is true when the provision job succeeded.
+	// NOTE: this field is part of the Cluster API contract, and it is used to orchestrate initial Machine provisioning.
+	// +optional
+	Provisioned *bool `json:"provisioned,omitempty"`
+}
+
+// SAFMachine's Ready condition and corresponding reasons, that are mirrored by Machine's InfrastructureReady condition.
+const (
+	// SAFMachineReadyCondition is true if the SAFMachine is provisioned and is not deleting.
+	SAFMachineReadyCondition = capiv1beta2.ReadyCondition
+
+	// SAFMachineReadyReason surfaces when the SAFMachine readiness criteria is met.
+	SAFMachineReadyReason = capiv1beta2.ReadyReason
+
+	// SAFMachineNotReadyReason surfaces when the SAFMachine readiness criteria is not met.
+	SAFMachineNotReadyReason = capiv1beta2.NotReadyReason
+
+	// SAFMachineReadyUnknownReason surfaces when at least one SAFMachine readiness criteria is unknown
+	// and no SAFMachine readiness criteria is not met.
+	SAFMachineReadyUnknownReason = capiv1beta2.ReadyUnknownReason
+)
+
+// SAFMachine's BootstrapDataAvailable condition and corresponding reasons.
+const (
+	// SAFMachineBootstrapDataAvailableCondition is true if the owner Machine has bootstrap data secret.
+	SAFMachineBootstrapDataAvailableCondition = "BootstrapDataAvailable"
+
+	// SAFMachineBootstrapDataAvailableReason surfaces when bootstrap data secret is available.
+	SAFMachineBootstrapDataAvailableReason = "Available"
+
+	// SAFMachineWaitingForMachineReason surfaces when the SAFMachine has no owner Machine yet.
+	SAFMachineWaitingForMachineReason = "WaitingForMachine"
+
+	// SAFMachineWaitingForBootstrapDataReason surfaces when the owner Machine has no bootstrap data secret yet.
+	SAFMachineWaitingForBootstrapDataReason = "WaitingForBootstrapData"
+)
+
+// SAFMachine's ProvisionJobSucceeded condition and corresponding reasons.
+const (
+	// SAFMachineProvisionJobSucceededCondition is true if the provision job completed successfully.
+	SAFMachineProvisionJobSucceededCondition = "ProvisionJobSucceeded"
+
+	// SAFMachineJobNotCreatedReason surfaces when the job is not created yet.
+	SAFMachineJobNotCreatedReason = "JobNotCreated"
+
+	// SAFMachineJobRunningReason surfaces when the job is created and is not finished yet.
+	SAFMachineJobRunningReason = "JobRunning"
+
+	// SAFMachineJobRetryingReason surfaces when the provision job failed and a new attempt is pending.
+	SAFMachineJobRetryingReason = "JobRetrying"
+
+	// SAFMachineJobFailedReason surfaces when the job failed.
+	SAFMachineJobFailedReason = "JobFailed"
+
+	// SAFMachineJobSucceededReason surfaces when the job succeeded.
+	SAFMachineJobSucceededReason = "JobSucceeded"
+)
+
+// SAFMachine's Provisioned condition and corresponding reasons.
+const (
+	// SAFMachineProvisionedCondition is true if status.initialization.provisioned is set.
+	SAFMachineProvisionedCondition = "Provisioned"
+
+	// SAFMachineProvisionedReason surfaces when the host is provisioned.
+	SAFMachineProvisionedReason = "Provisioned"
+
+	// SAFMachineProvisioningReason surfaces when the host is being provisioned.
+	SAFMachineProvisioningReason = "Provisioning"
+
+	// SAFMachineProvisioningFailedReason surfaces when provisioning of the host failed.
+	SAFMachineProvisioningFailedReason = "ProvisioningFailed"
+
+	// SAFMachineProvisionTimedOutReason surfaces when the host was not provisioned within provision timeout.
+	SAFMachineProvisionTimedOutReason = "ProvisionTimedOut"
+)
+
+// SAFMachine's Deprovisioned condition and corresponding reasons, it is reported only for deleting SAFMachine.
+const (
+	// SAFMachineDeprovisionedCondition is true if the deprovision job completed successfully.
+	SAFMachineDeprovisionedCondition = "Deprovisioned"
+
+	// SAFMachineDeprovisionedReason surfaces when the host is deprovisioned.
+	SAFMachineDeprovisionedReason = "Deprovisioned"
+
+	// SAFMachineDeprovisioningReason surfaces when the host is being deprovisioned.
+	SAFMachineDeprovisioningReason = "Deprovisioning"
+
+	// SAFMachineDeprovisioningFailedReason surfaces when deprovisioning of the host failed.
+	SAFMachineDeprovisioningFailedReason = "DeprovisioningFailed"
+)
+
+// SAFMachine's HostClaimed condition and corresponding reasons.
+const (
+	// SAFMachineHostClaimedCondition is true if the SAFMachine claimed a SAFHost by its hostSelector.
+	SAFMachineHostClaimedCondition = "HostClaimed"
+
+	// SAFMachineHostClaimedReason surfaces when the SAFHost is claimed.
+	SAFMachineHostClaimedReason = "HostClaimed"
+
+	// SAFMachineWaitingForHostReason surfaces when no available SAFHost matches hostSelector.
+	SAFMachineWaitingForHostReason = "WaitingForHost"
+
+	// SAFMachineHostNotFoundReason surfaces when the SAFHost in status.hostRef is not found,
+	// or is claimed by another SAFMachine.
+	SAFMachineHostNotFoundReason = "HostNotFound"
+)
+
+// SAFMachine's HostInspected condition and corresponding reasons, it is reported only with inspectionJob.
+const (
+	// SAFMachineHostInspectedCondition is true if the inspection job reported host facts and the host meets hostRequirements.
+	SAFMachineHostInspectedCondition = "HostInspected"
+
+	// SAFMachineHostInspectedReason surfaces when the host is inspected and meets requirements.
+	SAFMachineHostInspectedReason = "HostInspected"
+
+	// SAFMachineInspectingReason surfaces when the inspection job is not finished yet.
+	SAFMachineInspectingReason = "Inspecting"
+
+	// SAFMachineInspectionFailedReason surfaces when the inspection job failed.
+	// Deleting the failed job starts inspection again.
+	SAFMachineInspectionFailedReason = "InspectionFailed"
+
+	// SAFMachineRequirementsNotMetReason surfaces when the host doesn't meet hostRequirements.
+	SAFMachineRequirementsNotMetReason = "RequirementsNotMet"
+)
+
+// SAFMachine's Paused condition, it is reported with reasons from Cluster API.
+const (
+	// SAFMachinePausedCondition is true if the SAFMachine or its Cluster is paused.
+	SAFMachinePausedCondition = capiv1beta2.PausedCondition
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -66,6 +568,16 @@ type SAFMachine struct {
 	Status SAFMachineStatus `json:"status,omitempty,omitzero"`
 }
 
+// GetConditions returns the set of conditions for this object.
+func (m *SAFMachine) GetConditions() []metav1.Condition {
+	return m.Status.Conditions
+}
+
+// SetConditions sets conditions for an API object.
+func (m *SAFMachine) SetConditions(conditions []metav1.Condition) {
+	m.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // SAFMachineList contains a list of SAFMachine
@@ -0,0 +1,167 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+)
+
+// SAFMachinePoolSpec defines the desired state of SAFMachinePool
+type SAFMachinePoolSpec struct {
+	// providerIDList are provider IDs of provisioned instances of the pool.
+	// It is maintained by the controller and is read by the MachinePool.
+	// +optional
+	// +listType=atomic
+	ProviderIDList []string `json:"providerIDList,omitempty"`
+
+	// template describes SAFMachines, that are created for instances of the pool.
+	// Every instance runs its own provision and deprovision jobs, and claims its own host by hostSelector.
+	// +required
+	Template SAFMachinePoolMachineTemplate `json:"template"`
+}
+
+// SAFMachinePoolMachineTemplate describes SAFMachines of the pool.
+type SAFMachinePoolMachineTemplate struct {
+	// metadata is added to metadata of the SAFMachines.
+	// +optional
+	ObjectMeta capiv1beta2.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec of the SAFMachines. Its providerID is ignored, every instance gets its own one.
+	Spec SAFMachineSpec `json:"spec"`
+}
+
+// SAFMachinePoolStatus defines the observed state of SAFMachinePool.
+type SAFMachinePoolStatus struct {
+	// ready is true while all replicas of the MachinePool are provisioned, e.g. it is false during scaling up.
+	// NOTE: this field is read by Cluster API MachinePool controller, that doesn't read initialization yet.
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// initialization provides observations of the SAFMachinePool initialization process.
+	// NOTE: Fields in this struct are part of the Cluster API contract and are used to orchestrate initial MachinePool provisioning.
+	// +optional
+	Initialization SAFMachinePoolInitializationStatus `json:"initialization,omitempty,omitzero"`
+
+	// replicas is the number of provisioned instances of the pool.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// infrastructureMachineKind is the kind of instances of the pool, it is always SAFMachine.
+	// MachinePool creates a Machine for every instance.
+	// +optional
+	InfrastructureMachineKind string `json:"infrastructureMachineKind,omitempty"`
+
+	// The status of each condition is one of True, False, or Unknown.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// SAFMachinePoolInitializationStatus provides observations of the SAFMachinePool initialization process.
+// +kubebuilder:validation:MinProperties=1
+type SAFMachinePoolInitializationStatus struct {
+	// provisioned is true when all replicas of the MachinePool are provisioned for the first time.
+	// NOTE: this field is part of the Cluster API contract, and it is used to orchestrate initial MachinePool provisioning.
+	// +optional
+	Provisioned *bool `json:"provisioned,omitempty"`
+}
+
+// SAFMachinePool's Ready condition and corresponding reasons, that are mirrored by MachinePool.
+const (
+	// SAFMachinePoolReadyCondition is true if all replicas of the MachinePool are provisioned.
+	SAFMachinePoolReadyCondition = capiv1beta2.ReadyCondition
+
+	// SAFMachinePoolReadyReason surfaces when the SAFMachinePool readiness criteria is met.
+	SAFMachinePoolReadyReason = capiv1beta2.ReadyReason
+
+	// SAFMachinePoolNotReadyReason surfaces when the SAFMachinePool readiness criteria is not met.
+	SAFMachinePoolNotReadyReason = capiv1beta2.NotReadyReason
+
+	// SAFMachinePoolReadyUnknownReason surfaces when at least one SAFMachinePool readiness criteria is unknown
+	// and no SAFMachinePool readiness criteria is not met.
+	SAFMachinePoolReadyUnknownReason = capiv1beta2.ReadyUnknownReason
+)
+
+// SAFMachinePool's ReplicasProvisioned condition and corresponding reasons.
+const (
+	// SAFMachinePoolReplicasProvisionedCondition is true if the pool has as many provisioned instances,
+	// as the MachinePool wants.
+	SAFMachinePoolReplicasProvisionedCondition = "ReplicasProvisioned"
+
+	// SAFMachinePoolReplicasProvisionedReason surfaces when all replicas are provisioned.
+	SAFMachinePoolReplicasProvisionedReason = "Provisioned"
+
+	// SAFMachinePoolWaitingForMachinePoolReason surfaces when the SAFMachinePool has no owner MachinePool yet.
+	SAFMachinePoolWaitingForMachinePoolReason = "WaitingForMachinePool"
+
+	// SAFMachinePoolScalingUpReason surfaces when instances are being created or provisioned.
+	SAFMachinePoolScalingUpReason = "ScalingUp"
+
+	// SAFMachinePoolScalingDownReason surfaces when excess instances are being deleted.
+	SAFMachinePoolScalingDownReason = "ScalingDown"
+)
+
+// SAFMachinePool's Paused condition, it is reported with reasons from Cluster API.
+const (
+	// SAFMachinePoolPausedCondition is true if the SAFMachinePool or its Cluster is paused.
+	SAFMachinePoolPausedCondition = capiv1beta2.PausedCondition
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// SAFMachinePool is the Schema for the safmachinepools API
+type SAFMachinePool struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of SAFMachinePool
+	// +required
+	Spec SAFMachinePoolSpec `json:"spec"`
+
+	// status defines the observed state of SAFMachinePool
+	// +optional
+	Status SAFMachinePoolStatus `json:"status,omitempty,omitzero"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (p *SAFMachinePool) GetConditions() []metav1.Condition {
+	return p.Status.Conditions
+}
+
+// SetConditions sets conditions for an API object.
+func (p *SAFMachinePool) SetConditions(conditions []metav1.Condition) {
+	p.Status.Conditions = conditions
+}
+
+// +kubebuilder:object:root=true
+
+// SAFMachinePoolList contains a list of SAFMachinePool
+type SAFMachinePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SAFMachinePool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SAFMachinePool{}, &SAFMachinePoolList{})
+}
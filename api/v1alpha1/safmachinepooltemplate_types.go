@@ -0,0 +1,64 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+)
+
+// SAFMachinePoolTemplateSpec defines the desired state of SAFMachinePoolTemplate
+type SAFMachinePoolTemplateSpec struct {
+	Template SAFMachinePoolTemplateResource `json:"template"`
+}
+
+// SAFMachinePoolTemplateResource describes SAFMachinePools, created from the template, e.g. by ClusterClass.
+type SAFMachinePoolTemplateResource struct {
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	ObjectMeta capiv1beta2.ObjectMeta `json:"metadata,omitempty,omitzero"`
+	Spec       SAFMachinePoolSpec     `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// SAFMachinePoolTemplate is the Schema for the safmachinepooltemplates API
+type SAFMachinePoolTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of SAFMachinePoolTemplate
+	// +required
+	Spec SAFMachinePoolTemplateSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// SAFMachinePoolTemplateList contains a list of SAFMachinePoolTemplate
+type SAFMachinePoolTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SAFMachinePoolTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SAFMachinePoolTemplate{}, &SAFMachinePoolTemplateList{})
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capiv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 )
@@ -26,6 +27,16 @@ import (
 
 type SAFMachineTemplateSpec struct {
 	Template SAFMachineTemplateResource `json:"template"`
+
+	// capacity declares resources of nodes, created from the template, for cluster-autoscaler scale from zero.
+	// Declared resources override the ones, derived from SAFHosts matching hostSelector of the template.
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+
+	// nodeInfo declares the architecture and the operating system of nodes, created from the template.
+	// It overrides the one, derived from SAFHosts matching hostSelector of the template.
+	// +optional
+	NodeInfo *NodeInfo `json:"nodeInfo,omitempty"`
 }
 
 type SAFMachineTemplateResource struct {
@@ -36,22 +47,38 @@ type SAFMachineTemplateResource struct {
 	Spec       SAFMachineSpec         `json:"spec"`
 }
 
+// Architecture of the node.
+// +kubebuilder:validation:Enum=amd64;arm64;s390x;ppc64le
+type Architecture string
+
+// OperatingSystem of the node.
+// +kubebuilder:validation:Enum=linux;windows
+type OperatingSystem string
+
+// NodeInfo is the architecture and the operating system of the node.
+type NodeInfo struct {
+	// architecture of the node, e.g. amd64.
+	// +optional
+	Architecture Architecture `json:"architecture,omitempty"`
+
+	// operatingSystem of the node, e.g. linux.
+	// +optional
+	OperatingSystem OperatingSystem `json:"operatingSystem,omitempty"`
+}
+
 // SAFMachineTemplateStatus defines the observed state of SAFMachineTemplate.
 type SAFMachineTemplateStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
-
-	// For Kubernetes API conventions, see:
-	// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#typical-status-properties
-
-	// conditions represent the current state of the SAFMachineTemplate resource.
-	// Each condition has a unique type and reflects the status of a specific aspect of the resource.
-	//
-	// Standard condition types include:
-	// - "Available": the resource is fully functional
-	// - "Progressing": the resource is being created or updated
-	// - "Degraded": the resource failed to reach or maintain its desired state
-	//
+	// capacity of nodes, created from the template. It is read by cluster-autoscaler to scale from zero.
+	// It is the declared capacity of the template, completed with the smallest capacity of SAFHosts,
+	// matching hostSelector of the template.
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+
+	// nodeInfo of nodes, created from the template. It is read by cluster-autoscaler to scale from zero.
+	// It is the declared node info of the template, or the node info, shared by SAFHosts matching hostSelector of the template.
+	// +optional
+	NodeInfo *NodeInfo `json:"nodeInfo,omitempty"`
+
 	// The status of each condition is one of True, False, or Unknown.
 	// +listType=map
 	// +listMapKey=type
@@ -59,6 +86,19 @@ type SAFMachineTemplateStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// SAFMachineTemplate's Valid condition and corresponding reasons.
+const (
+	// SAFMachineTemplateValidCondition is true if Secrets, ConfigMaps and ServiceAccounts,
+	// referenced by provision and deprovision jobs of the template, exist.
+	SAFMachineTemplateValidCondition = "Valid"
+
+	// SAFMachineTemplateValidReason surfaces when all referenced resources exist.
+	SAFMachineTemplateValidReason = "Valid"
+
+	// SAFMachineTemplateMissingReferencesReason surfaces when some referenced resources don't exist.
+	SAFMachineTemplateMissingReferencesReason = "MissingReferences"
+)
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -79,6 +119,16 @@ type SAFMachineTemplate struct {
 	Status SAFMachineTemplateStatus `json:"status,omitempty,omitzero"`
 }
 
+// GetConditions returns the set of conditions for this object.
+func (t *SAFMachineTemplate) GetConditions() []metav1.Condition {
+	return t.Status.Conditions
+}
+
+// SetConditions sets conditions for an API object.
+func (t *SAFMachineTemplate) SetConditions(conditions []metav1.Condition) {
+	t.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // SAFMachineTemplateList contains a list of SAFMachineTemplate
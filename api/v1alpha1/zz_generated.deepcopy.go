@@ -21,10 +21,146 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/cluster-api/api/core/v1beta2"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionConfigSource) DeepCopyInto(out *ConnectionConfigSource) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(ConnectionConfigValueSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionConfigSource.
+func (in *ConnectionConfigSource) DeepCopy() *ConnectionConfigSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionConfigSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionConfigValueSource) DeepCopyInto(out *ConnectionConfigValueSource) {
+	*out = *in
+	in.SecretKeyRef.DeepCopyInto(&out.SecretKeyRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionConfigValueSource.
+func (in *ConnectionConfigValueSource) DeepCopy() *ConnectionConfigValueSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionConfigValueSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostDisk) DeepCopyInto(out *HostDisk) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostDisk.
+func (in *HostDisk) DeepCopy() *HostDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(HostDisk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostFacts) DeepCopyInto(out *HostFacts) {
+	*out = *in
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]HostDisk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NICs != nil {
+		in, out := &in.NICs, &out.NICs
+		*out = make([]HostNIC, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostFacts.
+func (in *HostFacts) DeepCopy() *HostFacts {
+	if in == nil {
+		return nil
+	}
+	out := new(HostFacts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostNIC) DeepCopyInto(out *HostNIC) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostNIC.
+func (in *HostNIC) DeepCopy() *HostNIC {
+	if in == nil {
+		return nil
+	}
+	out := new(HostNIC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostRequirements) DeepCopyInto(out *HostRequirements) {
+	*out = *in
+	if in.CPUs != nil {
+		in, out := &in.CPUs, &out.CPUs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DiskSize != nil {
+		in, out := &in.DiskSize, &out.DiskSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostRequirements.
+func (in *HostRequirements) DeepCopy() *HostRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(HostRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobTemplate) DeepCopyInto(out *JobTemplate) {
 	*out = *in
@@ -36,13 +172,381 @@ func (in *JobTemplate) DeepCopy() *JobTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(JobTemplate)
+	out := new(JobTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeInfo) DeepCopyInto(out *NodeInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeInfo.
+func (in *NodeInfo) DeepCopy() *NodeInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisionAttempt) DeepCopyInto(out *ProvisionAttempt) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionAttempt.
+func (in *ProvisionAttempt) DeepCopy() *ProvisionAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisionAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisionResult) DeepCopyInto(out *ProvisionResult) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]v1beta2.MachineAddress, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionResult.
+func (in *ProvisionResult) DeepCopy() *ProvisionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisionRetryPolicy) DeepCopyInto(out *ProvisionRetryPolicy) {
+	*out = *in
+	if in.InitialDelay != nil {
+		in, out := &in.InitialDelay, &out.InitialDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxDelay != nil {
+		in, out := &in.MaxDelay, &out.MaxDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisionRetryPolicy.
+func (in *ProvisionRetryPolicy) DeepCopy() *ProvisionRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisionRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFCluster) DeepCopyInto(out *SAFCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFCluster.
+func (in *SAFCluster) DeepCopy() *SAFCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SAFCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFClusterInitializationStatus) DeepCopyInto(out *SAFClusterInitializationStatus) {
+	*out = *in
+	if in.Provisioned != nil {
+		in, out := &in.Provisioned, &out.Provisioned
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterInitializationStatus.
+func (in *SAFClusterInitializationStatus) DeepCopy() *SAFClusterInitializationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFClusterInitializationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFClusterList) DeepCopyInto(out *SAFClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SAFCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterList.
+func (in *SAFClusterList) DeepCopy() *SAFClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SAFClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFClusterProvisionResult) DeepCopyInto(out *SAFClusterProvisionResult) {
+	*out = *in
+	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterProvisionResult.
+func (in *SAFClusterProvisionResult) DeepCopy() *SAFClusterProvisionResult {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFClusterProvisionResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFClusterSpec) DeepCopyInto(out *SAFClusterSpec) {
+	*out = *in
+	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.ProvisionJob != nil {
+		in, out := &in.ProvisionJob, &out.ProvisionJob
+		*out = new(JobTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeprovisionJob != nil {
+		in, out := &in.DeprovisionJob, &out.DeprovisionJob
+		*out = new(JobTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureDomains != nil {
+		in, out := &in.FailureDomains, &out.FailureDomains
+		*out = make([]SAFFailureDomain, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterSpec.
+func (in *SAFClusterSpec) DeepCopy() *SAFClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFClusterStatus) DeepCopyInto(out *SAFClusterStatus) {
+	*out = *in
+	in.Initialization.DeepCopyInto(&out.Initialization)
+	if in.FailureDomains != nil {
+		in, out := &in.FailureDomains, &out.FailureDomains
+		*out = make([]v1beta2.FailureDomain, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterStatus.
+func (in *SAFClusterStatus) DeepCopy() *SAFClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFClusterTemplate) DeepCopyInto(out *SAFClusterTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterTemplate.
+func (in *SAFClusterTemplate) DeepCopy() *SAFClusterTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFClusterTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SAFClusterTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFClusterTemplateList) DeepCopyInto(out *SAFClusterTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SAFClusterTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterTemplateList.
+func (in *SAFClusterTemplateList) DeepCopy() *SAFClusterTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFClusterTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SAFClusterTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFClusterTemplateResource) DeepCopyInto(out *SAFClusterTemplateResource) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterTemplateResource.
+func (in *SAFClusterTemplateResource) DeepCopy() *SAFClusterTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFClusterTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFClusterTemplateSpec) DeepCopyInto(out *SAFClusterTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterTemplateSpec.
+func (in *SAFClusterTemplateSpec) DeepCopy() *SAFClusterTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFClusterTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFFailureDomain) DeepCopyInto(out *SAFFailureDomain) {
+	*out = *in
+	if in.ControlPlane != nil {
+		in, out := &in.ControlPlane, &out.ControlPlane
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HostSelector != nil {
+		in, out := &in.HostSelector, &out.HostSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JobNodeSelector != nil {
+		in, out := &in.JobNodeSelector, &out.JobNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFFailureDomain.
+func (in *SAFFailureDomain) DeepCopy() *SAFFailureDomain {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFFailureDomain)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFCluster) DeepCopyInto(out *SAFCluster) {
+func (in *SAFHost) DeepCopyInto(out *SAFHost) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -50,18 +554,18 @@ func (in *SAFCluster) DeepCopyInto(out *SAFCluster) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFCluster.
-func (in *SAFCluster) DeepCopy() *SAFCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFHost.
+func (in *SAFHost) DeepCopy() *SAFHost {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFCluster)
+	out := new(SAFHost)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SAFCluster) DeepCopyObject() runtime.Object {
+func (in *SAFHost) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -69,31 +573,31 @@ func (in *SAFCluster) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFClusterList) DeepCopyInto(out *SAFClusterList) {
+func (in *SAFHostList) DeepCopyInto(out *SAFHostList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SAFCluster, len(*in))
+		*out = make([]SAFHost, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterList.
-func (in *SAFClusterList) DeepCopy() *SAFClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFHostList.
+func (in *SAFHostList) DeepCopy() *SAFHostList {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFClusterList)
+	out := new(SAFHostList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SAFClusterList) DeepCopyObject() runtime.Object {
+func (in *SAFHostList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -101,27 +605,63 @@ func (in *SAFClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFClusterSpec) DeepCopyInto(out *SAFClusterSpec) {
+func (in *SAFHostSpec) DeepCopyInto(out *SAFHostSpec) {
 	*out = *in
-	if in.Foo != nil {
-		in, out := &in.Foo, &out.Foo
-		*out = new(string)
+	if in.ConnectionConfig != nil {
+		in, out := &in.ConnectionConfig, &out.ConnectionConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConnectionConfigFrom != nil {
+		in, out := &in.ConnectionConfigFrom, &out.ConnectionConfigFrom
+		*out = make([]ConnectionConfigSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SSH != nil {
+		in, out := &in.SSH, &out.SSH
+		*out = new(SSHProvisioner)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CleaningJob != nil {
+		in, out := &in.CleaningJob, &out.CleaningJob
+		*out = new(JobTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeInfo != nil {
+		in, out := &in.NodeInfo, &out.NodeInfo
+		*out = new(NodeInfo)
+		**out = **in
+	}
+	if in.ConsumerRef != nil {
+		in, out := &in.ConsumerRef, &out.ConsumerRef
+		*out = new(corev1.ObjectReference)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterSpec.
-func (in *SAFClusterSpec) DeepCopy() *SAFClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFHostSpec.
+func (in *SAFHostSpec) DeepCopy() *SAFHostSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFClusterSpec)
+	out := new(SAFHostSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFClusterStatus) DeepCopyInto(out *SAFClusterStatus) {
+func (in *SAFHostStatus) DeepCopyInto(out *SAFHostStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -132,36 +672,37 @@ func (in *SAFClusterStatus) DeepCopyInto(out *SAFClusterStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterStatus.
-func (in *SAFClusterStatus) DeepCopy() *SAFClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFHostStatus.
+func (in *SAFHostStatus) DeepCopy() *SAFHostStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFClusterStatus)
+	out := new(SAFHostStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFClusterTemplate) DeepCopyInto(out *SAFClusterTemplate) {
+func (in *SAFMachine) DeepCopyInto(out *SAFMachine) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterTemplate.
-func (in *SAFClusterTemplate) DeepCopy() *SAFClusterTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachine.
+func (in *SAFMachine) DeepCopy() *SAFMachine {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFClusterTemplate)
+	out := new(SAFMachine)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SAFClusterTemplate) DeepCopyObject() runtime.Object {
+func (in *SAFMachine) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -169,31 +710,51 @@ func (in *SAFClusterTemplate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFClusterTemplateList) DeepCopyInto(out *SAFClusterTemplateList) {
+func (in *SAFMachineInitializationStatus) DeepCopyInto(out *SAFMachineInitializationStatus) {
+	*out = *in
+	if in.Provisioned != nil {
+		in, out := &in.Provisioned, &out.Provisioned
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachineInitializationStatus.
+func (in *SAFMachineInitializationStatus) DeepCopy() *SAFMachineInitializationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFMachineInitializationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFMachineList) DeepCopyInto(out *SAFMachineList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SAFClusterTemplate, len(*in))
+		*out = make([]SAFMachine, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterTemplateList.
-func (in *SAFClusterTemplateList) DeepCopy() *SAFClusterTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachineList.
+func (in *SAFMachineList) DeepCopy() *SAFMachineList {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFClusterTemplateList)
+	out := new(SAFMachineList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SAFClusterTemplateList) DeepCopyObject() runtime.Object {
+func (in *SAFMachineList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -201,59 +762,165 @@ func (in *SAFClusterTemplateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFClusterTemplateResource) DeepCopyInto(out *SAFClusterTemplateResource) {
+func (in *SAFMachinePool) DeepCopyInto(out *SAFMachinePool) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterTemplateResource.
-func (in *SAFClusterTemplateResource) DeepCopy() *SAFClusterTemplateResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePool.
+func (in *SAFMachinePool) DeepCopy() *SAFMachinePool {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFClusterTemplateResource)
+	out := new(SAFMachinePool)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SAFMachinePool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFClusterTemplateSpec) DeepCopyInto(out *SAFClusterTemplateSpec) {
+func (in *SAFMachinePoolInitializationStatus) DeepCopyInto(out *SAFMachinePoolInitializationStatus) {
+	*out = *in
+	if in.Provisioned != nil {
+		in, out := &in.Provisioned, &out.Provisioned
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePoolInitializationStatus.
+func (in *SAFMachinePoolInitializationStatus) DeepCopy() *SAFMachinePoolInitializationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFMachinePoolInitializationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFMachinePoolList) DeepCopyInto(out *SAFMachinePoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SAFMachinePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePoolList.
+func (in *SAFMachinePoolList) DeepCopy() *SAFMachinePoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFMachinePoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SAFMachinePoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFMachinePoolMachineTemplate) DeepCopyInto(out *SAFMachinePoolMachineTemplate) {
 	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePoolMachineTemplate.
+func (in *SAFMachinePoolMachineTemplate) DeepCopy() *SAFMachinePoolMachineTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFMachinePoolMachineTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFMachinePoolSpec) DeepCopyInto(out *SAFMachinePoolSpec) {
+	*out = *in
+	if in.ProviderIDList != nil {
+		in, out := &in.ProviderIDList, &out.ProviderIDList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	in.Template.DeepCopyInto(&out.Template)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFClusterTemplateSpec.
-func (in *SAFClusterTemplateSpec) DeepCopy() *SAFClusterTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePoolSpec.
+func (in *SAFMachinePoolSpec) DeepCopy() *SAFMachinePoolSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFClusterTemplateSpec)
+	out := new(SAFMachinePoolSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFMachine) DeepCopyInto(out *SAFMachine) {
+func (in *SAFMachinePoolStatus) DeepCopyInto(out *SAFMachinePoolStatus) {
+	*out = *in
+	in.Initialization.DeepCopyInto(&out.Initialization)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePoolStatus.
+func (in *SAFMachinePoolStatus) DeepCopy() *SAFMachinePoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFMachinePoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFMachinePoolTemplate) DeepCopyInto(out *SAFMachinePoolTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachine.
-func (in *SAFMachine) DeepCopy() *SAFMachine {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePoolTemplate.
+func (in *SAFMachinePoolTemplate) DeepCopy() *SAFMachinePoolTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFMachine)
+	out := new(SAFMachinePoolTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SAFMachine) DeepCopyObject() runtime.Object {
+func (in *SAFMachinePoolTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -261,37 +928,70 @@ func (in *SAFMachine) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SAFMachineList) DeepCopyInto(out *SAFMachineList) {
+func (in *SAFMachinePoolTemplateList) DeepCopyInto(out *SAFMachinePoolTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SAFMachine, len(*in))
+		*out = make([]SAFMachinePoolTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachineList.
-func (in *SAFMachineList) DeepCopy() *SAFMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePoolTemplateList.
+func (in *SAFMachinePoolTemplateList) DeepCopy() *SAFMachinePoolTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(SAFMachineList)
+	out := new(SAFMachinePoolTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SAFMachineList) DeepCopyObject() runtime.Object {
+func (in *SAFMachinePoolTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFMachinePoolTemplateResource) DeepCopyInto(out *SAFMachinePoolTemplateResource) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePoolTemplateResource.
+func (in *SAFMachinePoolTemplateResource) DeepCopy() *SAFMachinePoolTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFMachinePoolTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAFMachinePoolTemplateSpec) DeepCopyInto(out *SAFMachinePoolTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachinePoolTemplateSpec.
+func (in *SAFMachinePoolTemplateSpec) DeepCopy() *SAFMachinePoolTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SAFMachinePoolTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SAFMachineSpec) DeepCopyInto(out *SAFMachineSpec) {
 	*out = *in
@@ -302,8 +1002,50 @@ func (in *SAFMachineSpec) DeepCopyInto(out *SAFMachineSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.ConnectionConfigFrom != nil {
+		in, out := &in.ConnectionConfigFrom, &out.ConnectionConfigFrom
+		*out = make([]ConnectionConfigSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HostSelector != nil {
+		in, out := &in.HostSelector, &out.HostSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	in.ProvisionJob.DeepCopyInto(&out.ProvisionJob)
 	in.DeprovisionJob.DeepCopyInto(&out.DeprovisionJob)
+	if in.InspectionJob != nil {
+		in, out := &in.InspectionJob, &out.InspectionJob
+		*out = new(JobTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostRequirements != nil {
+		in, out := &in.HostRequirements, &out.HostRequirements
+		*out = new(HostRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SSH != nil {
+		in, out := &in.SSH, &out.SSH
+		*out = new(SSHProvisioner)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(ProvisionRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Simulate != nil {
+		in, out := &in.Simulate, &out.Simulate
+		*out = new(SimulateConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProvisionTimeout != nil {
+		in, out := &in.ProvisionTimeout, &out.ProvisionTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachineSpec.
@@ -319,6 +1061,29 @@ func (in *SAFMachineSpec) DeepCopy() *SAFMachineSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SAFMachineStatus) DeepCopyInto(out *SAFMachineStatus) {
 	*out = *in
+	in.Initialization.DeepCopyInto(&out.Initialization)
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]v1beta2.MachineAddress, len(*in))
+		copy(*out, *in)
+	}
+	if in.HostRef != nil {
+		in, out := &in.HostRef, &out.HostRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.HostFacts != nil {
+		in, out := &in.HostFacts, &out.HostFacts
+		*out = new(HostFacts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProvisionAttempts != nil {
+		in, out := &in.ProvisionAttempts, &out.ProvisionAttempts
+		*out = make([]ProvisionAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -418,6 +1183,18 @@ func (in *SAFMachineTemplateResource) DeepCopy() *SAFMachineTemplateResource {
 func (in *SAFMachineTemplateSpec) DeepCopyInto(out *SAFMachineTemplateSpec) {
 	*out = *in
 	in.Template.DeepCopyInto(&out.Template)
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeInfo != nil {
+		in, out := &in.NodeInfo, &out.NodeInfo
+		*out = new(NodeInfo)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAFMachineTemplateSpec.
@@ -433,6 +1210,18 @@ func (in *SAFMachineTemplateSpec) DeepCopy() *SAFMachineTemplateSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SAFMachineTemplateStatus) DeepCopyInto(out *SAFMachineTemplateStatus) {
 	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeInfo != nil {
+		in, out := &in.NodeInfo, &out.NodeInfo
+		*out = new(NodeInfo)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -451,3 +1240,44 @@ func (in *SAFMachineTemplateStatus) DeepCopy() *SAFMachineTemplateStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHProvisioner) DeepCopyInto(out *SSHProvisioner) {
+	*out = *in
+	in.KeySecretRef.DeepCopyInto(&out.KeySecretRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHProvisioner.
+func (in *SSHProvisioner) DeepCopy() *SSHProvisioner {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHProvisioner)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SimulateConfig) DeepCopyInto(out *SimulateConfig) {
+	*out = *in
+	if in.Delay != nil {
+		in, out := &in.Delay, &out.Delay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FailurePercent != nil {
+		in, out := &in.FailurePercent, &out.FailurePercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SimulateConfig.
+func (in *SimulateConfig) DeepCopy() *SimulateConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SimulateConfig)
+	in.DeepCopyInto(out)
+	return out
+}
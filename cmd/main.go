@@ -20,15 +20,21 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cluster-api/controllers/clustercache"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -37,7 +43,16 @@ import (
 
 	infrastructurev1alpha1 "github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
 	"github.com/GoodCoffeeLover/saf-api/internal/controller/safcluster"
+	"github.com/GoodCoffeeLover/saf-api/internal/controller/safhost"
 	"github.com/GoodCoffeeLover/saf-api/internal/controller/safmachine"
+	"github.com/GoodCoffeeLover/saf-api/internal/controller/safmachinepool"
+	"github.com/GoodCoffeeLover/saf-api/internal/controller/safmachinetemplate"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
+	jobprovisioner "github.com/GoodCoffeeLover/saf-api/internal/provisioner/job"
+	noopprovisioner "github.com/GoodCoffeeLover/saf-api/internal/provisioner/noop"
+	simulateprovisioner "github.com/GoodCoffeeLover/saf-api/internal/provisioner/simulate"
+	safclusterwebhook "github.com/GoodCoffeeLover/saf-api/internal/webhook/safcluster"
+	safmachinewebhook "github.com/GoodCoffeeLover/saf-api/internal/webhook/safmachine"
 	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 	// +kubebuilder:scaffold:imports
 )
@@ -64,6 +79,12 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var provisionTimeout time.Duration
+	var connectionConfigEnvPrefix string
+	var sshImage string
+	var simulate bool
+	var simulateDelay time.Duration
+	var simulateFailurePercent int
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -82,6 +103,21 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&provisionTimeout, "provision-timeout", 0,
+		"The default time for SAFMachine to be provisioned, before it is marked as failed. "+
+			"SAFMachine's spec.provisionTimeout overrides it. Leave as 0 to wait for provisioning forever.")
+	flag.StringVar(&connectionConfigEnvPrefix, "connection-config-env-prefix", jobprovisioner.DefaultConnectionConfigEnvPrefix,
+		"The prefix of env names, that expose SAFMachine's connection config to provision and deprovision jobs.")
+	flag.StringVar(&sshImage, "ssh-image", jobprovisioner.DefaultSSHImage,
+		"The image of jobs, that provision SAFMachines over ssh. It must have sh and ssh client or apk.")
+	flag.BoolVar(&simulate, "simulate", false,
+		"If set, every SAFMachine is provisioned by the Simulate provisioner, whatever its spec.provisioner is, "+
+			"jobs of SAFClusters are simulated and released SAFHosts are cleaned at once. "+
+			"No pods are run, it is intended for testing Cluster API flows without real hosts.")
+	flag.DurationVar(&simulateDelay, "simulate-delay", simulateprovisioner.DefaultDelay,
+		"The default duration of simulated provisioning and deprovisioning.")
+	flag.IntVar(&simulateFailurePercent, "simulate-failure-percent", 0,
+		"The default chance of simulated provisioning attempt to fail, in percent.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -158,7 +194,14 @@ func main() {
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
+		Scheme: scheme,
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				// Secrets, ConfigMaps and ServiceAccounts are read rarely, so don't cache all of them,
+				// only referenced ones are read directly.
+				DisableFor: []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}, &corev1.ServiceAccount{}},
+			},
+		},
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
@@ -181,20 +224,101 @@ func main() {
 		os.Exit(1)
 	}
 
+	ctx := ctrl.SetupSignalHandler()
+
+	clusterCache, err := clustercache.SetupWithManager(ctx, mgr, clustercache.Options{
+		SecretClient: mgr.GetAPIReader(),
+		Client: clustercache.ClientOptions{
+			UserAgent: remote.DefaultClusterAPIUserAgent("saf-api-controller-manager"),
+		},
+	}, controller.Options{})
+	if err != nil {
+		setupLog.Error(err, "unable to create cluster cache")
+		os.Exit(1)
+	}
+
+	simulateProvisioner := &simulateprovisioner.Provisioner{
+		Delay:          simulateDelay,
+		FailurePercent: int32(min(max(simulateFailurePercent, 0), 100)),
+	}
+	jobProvisioner := &jobprovisioner.Provisioner{
+		Client:                    mgr.GetClient(),
+		Scheme:                    mgr.GetScheme(),
+		APIReader:                 mgr.GetAPIReader(),
+		ConnectionConfigEnvPrefix: connectionConfigEnvPrefix,
+		SSHImage:                  sshImage,
+	}
+	var clusterProvisioner provisioner.ClusterProvisioner = jobProvisioner
+	var cleaner provisioner.Cleaner = jobProvisioner
+	provisioners := map[string]provisioner.Provisioner{
+		infrastructurev1alpha1.JobProvisioner:      jobProvisioner,
+		infrastructurev1alpha1.NoopProvisioner:     &noopprovisioner.Provisioner{},
+		infrastructurev1alpha1.SimulateProvisioner: simulateProvisioner,
+	}
+	if simulate {
+		setupLog.Info("simulating provisioning of every SAFCluster and SAFMachine and cleaning of every SAFHost, " +
+			"no jobs are run")
+		clusterProvisioner = simulateProvisioner
+		cleaner = simulateProvisioner
+		for name := range provisioners {
+			provisioners[name] = simulateProvisioner
+		}
+	}
 	if err := (&safcluster.Reconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Provisioner: clusterProvisioner,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SAFCluster")
 		os.Exit(1)
 	}
 	if err := (&safmachine.Reconciler{
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		ClusterCache:     clusterCache,
+		ProvisionTimeout: provisionTimeout,
+		Provisioners:     provisioners,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SAFMachine")
+		os.Exit(1)
+	}
+	if err := (&safhost.Reconciler{
+		Client:  mgr.GetClient(),
+		Scheme:  mgr.GetScheme(),
+		Cleaner: cleaner,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SAFHost")
+		os.Exit(1)
+	}
+	if err := (&safmachinepool.Reconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "SAFMachine")
+		setupLog.Error(err, "unable to create controller", "controller", "SAFMachinePool")
 		os.Exit(1)
 	}
+	if err := (&safmachinetemplate.Reconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SAFMachineTemplate")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := (&safmachinewebhook.Webhook{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SAFMachine")
+			os.Exit(1)
+		}
+		if err := (&safmachinewebhook.TemplateWebhook{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SAFMachineTemplate")
+			os.Exit(1)
+		}
+		if err := (&safclusterwebhook.TemplateWebhook{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SAFClusterTemplate")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -207,7 +331,7 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
@@ -18,46 +18,324 @@ package safcluster
 
 import (
 	"context"
+	"fmt"
 
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/utils/ptr"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/finalizers"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
+	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	infrastructurev1alpha1 "github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
 )
 
 // Reconciler reconciles a SAFCluster object
 type Reconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Provisioner provisions and deprovisions infrastructure of SAFClusters, e.g. by jobs.
+	Provisioner provisioner.ClusterProvisioner
+}
+
+type scope struct {
+	cluster           *capv1beta2.Cluster
+	safCluster        *infrastructurev1alpha1.SAFCluster
+	provisionStatus   *provisioner.Status
+	deprovisionStatus *provisioner.Status
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safclusters/finalizers,verbs=update
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the SAFCluster object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
-func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = logf.FromContext(ctx)
-
-	// TODO(user): your logic here
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	l := logf.FromContext(ctx)
+
+	safcl := &infrastructurev1alpha1.SAFCluster{}
+	if err := r.Get(ctx, req.NamespacedName, safcl); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("get saf cluster: %w", err))
+	}
+
+	cl, err := util.GetOwnerCluster(ctx, r.Client, safcl.ObjectMeta)
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, fmt.Errorf("get owner cluster: %w", err)
+	}
+	s := &scope{
+		cluster:    cl,
+		safCluster: safcl,
+	}
+
+	if isPaused, requeue, err := paused.EnsurePausedCondition(ctx, r.Client, s.cluster, s.safCluster); err != nil || isPaused || requeue {
+		return ctrl.Result{}, err
+	}
+
+	if changed, err := finalizers.EnsureFinalizer(ctx, r.Client, safcl, infrastructurev1alpha1.SAFClusterFinalizer); changed || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pacher, err := patch.NewHelper(safcl, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("make patcher: %w", err)
+	}
+	if err := restoreStatus(safcl); err != nil {
+		return ctrl.Result{}, fmt.Errorf("restore status: %w", err)
+	}
+	defer func() {
+		calculateStatus(ctx, s)
+		if err := saveStatus(safcl); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+		opts := []patch.Option{
+			patch.WithOwnedConditions{Conditions: []string{
+				infrastructurev1alpha1.SAFClusterReadyCondition,
+				infrastructurev1alpha1.SAFClusterControlPlaneEndpointAvailableCondition,
+				infrastructurev1alpha1.SAFClusterProvisionJobSucceededCondition,
+				infrastructurev1alpha1.SAFClusterDeprovisionJobSucceededCondition,
+				infrastructurev1alpha1.SAFClusterPausedCondition,
+			}},
+		}
+		if reterr == nil {
+			opts = append(opts, patch.WithStatusObservedGeneration{})
+		}
+		if err := pacher.Patch(ctx, safcl, opts...); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	if safcl.GetDeletionTimestamp() != nil {
+		return r.reconcileDelete(ctx, s)
+	}
+
+	syncFailureDomains(safcl)
+
+	if cl == nil {
+		// will requeue, when Cluster sets owner reference
+		l.Info("waiting for Cluster to set owner reference")
+		return ctrl.Result{}, nil
+	}
+
+	return r.provision(ctx, s)
+}
+
+// provision runs the provision job once, if the SAFCluster has one, and marks the SAFCluster provisioned,
+// as soon as the control plane endpoint is known.
+func (r *Reconciler) provision(ctx context.Context, s *scope) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "provision")
+	ctx = logf.IntoContext(ctx, l)
+	safcl := s.safCluster
+
+	if ptr.Deref(safcl.Status.Initialization.Provisioned, false) {
+		return ctrl.Result{}, nil
+	}
+
+	if safcl.Spec.ProvisionJob != nil {
+		status, err := r.Provisioner.ProvisionCluster(ctx, safcl)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("start cluster provisioning: %w", err)
+		}
+		s.provisionStatus = &status
+
+		switch status.State {
+		case provisioner.StateFailed:
+			// will requeue, when the failed job is deleted
+			l.Info("provisioning failed", "provision_job_name", status.Name, "message", status.Message)
+			return ctrl.Result{}, nil
+		case provisioner.StateSucceeded:
+			if result := status.ClusterResult; result != nil && !result.ControlPlaneEndpoint.IsZero() &&
+				safcl.Spec.ControlPlaneEndpoint.IsZero() {
+				l.Info("set control plane endpoint reported by provision job", "provision_job_name", status.Name,
+					"control_plane_endpoint", result.ControlPlaneEndpoint.String())
+				safcl.Spec.ControlPlaneEndpoint = result.ControlPlaneEndpoint
+			}
+		default:
+			// will requeue on job update
+			l.Info("provisioning is not finished", "provision_job_name", status.Name)
+			return ctrl.Result{RequeueAfter: status.RequeueAfter}, nil
+		}
+	}
+
+	if !safcl.Spec.ControlPlaneEndpoint.IsValid() {
+		l.Info("waiting for control plane endpoint")
+		return ctrl.Result{}, nil
+	}
+
+	l.Info("saf cluster is provisioned", "control_plane_endpoint", safcl.Spec.ControlPlaneEndpoint.String())
+	safcl.Status.Initialization.Provisioned = ptr.To(true)
+	return ctrl.Result{}, nil
+}
+
+// reconcileDelete removes the finalizer, after the deprovision job of the SAFCluster succeeded.
+func (r *Reconciler) reconcileDelete(ctx context.Context, s *scope) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "deprovision")
+	ctx = logf.IntoContext(ctx, l)
+	safcl := s.safCluster
+
+	if !controllerutil.ContainsFinalizer(safcl, infrastructurev1alpha1.SAFClusterFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// cancel provisioning, so it doesn't set up resources, while they are cleaned up
+	if safcl.Spec.ProvisionJob != nil && !ptr.Deref(safcl.Status.Initialization.Provisioned, false) {
+		status, err := r.Provisioner.ClusterStatus(ctx, safcl, provisioner.OperationProvisionCluster)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("get status of cluster provisioning: %w", err)
+		}
+		if status.State == provisioner.StateRunning {
+			s.provisionStatus = &status
+			if status.Canceling {
+				// will requeue on job deletion
+				l.Info("waiting for provisioning to be canceled", "provision_job_name", status.Name)
+				return ctrl.Result{RequeueAfter: status.RequeueAfter}, nil
+			}
+			l.Info("cancel running provisioning", "provision_job_name", status.Name)
+			return ctrl.Result{}, r.Provisioner.CancelCluster(ctx, safcl, provisioner.OperationProvisionCluster)
+		}
+	}
 
+	if safcl.Spec.DeprovisionJob != nil {
+		status, err := r.Provisioner.DeprovisionCluster(ctx, safcl)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("start cluster deprovisioning: %w", err)
+		}
+		s.deprovisionStatus = &status
+
+		switch status.State {
+		case provisioner.StateFailed:
+			return ctrl.Result{}, fmt.Errorf("deprovision job %s failed: %s", status.Name, status.Message)
+		case provisioner.StateSucceeded:
+		default:
+			// will requeue on job update
+			l.Info("deprovisioning is not finished", "deprovision_job_name", status.Name)
+			return ctrl.Result{RequeueAfter: status.RequeueAfter}, nil
+		}
+	}
+
+	l.Info("saf cluster is deprovisioned, removing finalizer")
+	controllerutil.RemoveFinalizer(safcl, infrastructurev1alpha1.SAFClusterFinalizer)
 	return ctrl.Result{}, nil
 }
 
+// syncFailureDomains reports failure domains of the spec to Cluster API.
+func syncFailureDomains(safcl *infrastructurev1alpha1.SAFCluster) {
+	safcl.Status.FailureDomains = nil
+	for _, fd := range safcl.Spec.FailureDomains {
+		safcl.Status.FailureDomains = append(safcl.Status.FailureDomains, capv1beta2.FailureDomain{
+			Name:         fd.Name,
+			ControlPlane: fd.ControlPlane,
+			Attributes:   fd.Attributes,
+		})
+	}
+}
+
+func calculateStatus(ctx context.Context, s *scope) {
+	l := logf.FromContext(ctx)
+	safcl := s.safCluster
+
+	setCondition := func(conditionType string, status metav1.ConditionStatus, reason, message string) {
+		conditions.Set(safcl, metav1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: safcl.Generation,
+		})
+	}
+
+	switch {
+	case safcl.Spec.ControlPlaneEndpoint.IsValid():
+		setCondition(infrastructurev1alpha1.SAFClusterControlPlaneEndpointAvailableCondition, metav1.ConditionTrue,
+			infrastructurev1alpha1.SAFClusterControlPlaneEndpointAvailableReason, "")
+	case safcl.Spec.ProvisionJob != nil:
+		setCondition(infrastructurev1alpha1.SAFClusterControlPlaneEndpointAvailableCondition, metav1.ConditionFalse,
+			infrastructurev1alpha1.SAFClusterWaitingForControlPlaneEndpointReason,
+			"Waiting for spec.controlPlaneEndpoint or provision job to report it")
+	default:
+		setCondition(infrastructurev1alpha1.SAFClusterControlPlaneEndpointAvailableCondition, metav1.ConditionFalse,
+			infrastructurev1alpha1.SAFClusterWaitingForControlPlaneEndpointReason, "Waiting for spec.controlPlaneEndpoint")
+	}
+
+	if safcl.Spec.ProvisionJob != nil {
+		status, reason, message := jobConditionFields(s.provisionStatus,
+			ptr.Deref(safcl.Status.Initialization.Provisioned, false))
+		setCondition(infrastructurev1alpha1.SAFClusterProvisionJobSucceededCondition, status, reason, message)
+	}
+
+	if safcl.GetDeletionTimestamp() != nil && safcl.Spec.DeprovisionJob != nil {
+		status, reason, message := jobConditionFields(s.deprovisionStatus,
+			!controllerutil.ContainsFinalizer(safcl, infrastructurev1alpha1.SAFClusterFinalizer))
+		setCondition(infrastructurev1alpha1.SAFClusterDeprovisionJobSucceededCondition, status, reason, message)
+	}
+
+	if err := conditions.SetSummaryCondition(safcl, safcl, infrastructurev1alpha1.SAFClusterReadyCondition,
+		conditions.ForConditionTypes{
+			infrastructurev1alpha1.SAFClusterControlPlaneEndpointAvailableCondition,
+			infrastructurev1alpha1.SAFClusterProvisionJobSucceededCondition,
+			infrastructurev1alpha1.SAFClusterDeprovisionJobSucceededCondition,
+		},
+		conditions.IgnoreTypesIfMissing{
+			infrastructurev1alpha1.SAFClusterProvisionJobSucceededCondition,
+			infrastructurev1alpha1.SAFClusterDeprovisionJobSucceededCondition,
+		},
+	); err != nil {
+		l.Error(err, "set ready condition")
+	}
+}
+
+// jobConditionFields describes status of the job as condition fields. Succeeded job may be already removed,
+// so done reports, that the job succeeded some time ago.
+func jobConditionFields(status *provisioner.Status, done bool) (metav1.ConditionStatus, string, string) {
+	if done {
+		return metav1.ConditionTrue, infrastructurev1alpha1.SAFClusterJobSucceededReason, ""
+	}
+	if status == nil || status.State == provisioner.StateNotStarted {
+		return metav1.ConditionFalse, infrastructurev1alpha1.SAFClusterJobNotCreatedReason, "Job is not created yet"
+	}
+
+	switch status.State {
+	case provisioner.StateRunning:
+		return metav1.ConditionFalse, infrastructurev1alpha1.SAFClusterJobRunningReason,
+			fmt.Sprintf("Job %s is running", status.Name)
+	case provisioner.StateFailed:
+		return metav1.ConditionFalse, infrastructurev1alpha1.SAFClusterJobFailedReason,
+			fmt.Sprintf("Job %s failed: %s", status.Name, status.Message)
+	}
+	return metav1.ConditionTrue, infrastructurev1alpha1.SAFClusterJobSucceededReason, ""
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	l := mgr.GetLogger().WithValues("controller", "safcluster")
+	ctx := logf.IntoContext(context.Background(), l)
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&infrastructurev1alpha1.SAFCluster{}).
+		Owns(&batchv1.Job{}).
+		Watches(
+			&capv1beta2.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(util.ClusterToInfrastructureMapFunc(ctx,
+				infrastructurev1alpha1.GroupVersion.WithKind(infrastructurev1alpha1.SAFClusterKind),
+				mgr.GetClient(), &infrastructurev1alpha1.SAFCluster{})),
+			builder.WithPredicates(predicates.ClusterPausedTransitions(mgr.GetScheme(), l)),
+		).
 		Named("safcluster").
 		Complete(r)
 }
@@ -21,65 +21,495 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
 	infrastructurev1alpha1 "github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
 	"github.com/GoodCoffeeLover/saf-api/internal/controller/safcluster"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner/job"
 )
 
 var _ = Describe("SAFCluster Controller", func() {
-	Context("When reconciling a resource", func() {
-		const resourceName = "test-resource"
+	ctx := context.Background()
+
+	newReconciler := func() *safcluster.Reconciler {
+		return &safcluster.Reconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Provisioner: &job.Provisioner{
+				Client:                    k8sClient,
+				Scheme:                    k8sClient.Scheme(),
+				ConnectionConfigEnvPrefix: "SAF_",
+			},
+		}
+	}
+
+	reconcileCluster := func(safcl *infrastructurev1alpha1.SAFCluster) {
+		_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safcl)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(safcl), safcl)).To(Succeed())
+	}
 
-		ctx := context.Background()
+	// createSAFCluster creates the SAFCluster, which is deleted through the reconciler on cleanup.
+	createSAFCluster := func(safcl *infrastructurev1alpha1.SAFCluster) {
+		Expect(k8sClient.Create(ctx, safcl)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, safcl))).To(Succeed())
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safcl)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(safcl), safcl))).To(BeTrue())
+		})
+	}
 
-		typeNamespacedName := types.NamespacedName{
-			Name:      resourceName,
-			Namespace: "default", // TODO(user):Modify as needed
+	// createOwnerCluster creates the Cluster and sets it as the owner of the SAFCluster, like Cluster API does.
+	createOwnerCluster := func(safcl *infrastructurev1alpha1.SAFCluster) *capv1beta2.Cluster {
+		cluster := &capv1beta2.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: safcl.Name, Namespace: safcl.Namespace},
+			Spec: capv1beta2.ClusterSpec{
+				InfrastructureRef: capv1beta2.ContractVersionedObjectReference{
+					APIGroup: infrastructurev1alpha1.GroupVersion.Group,
+					Kind:     infrastructurev1alpha1.SAFClusterKind,
+					Name:     safcl.Name,
+				},
+			},
 		}
-		safcl := &infrastructurev1alpha1.SAFCluster{}
-
-		BeforeEach(func() {
-			By("creating the custom resource for the Kind SAFCluster")
-			err := k8sClient.Get(ctx, typeNamespacedName, safcl)
-			if err != nil && errors.IsNotFound(err) {
-				resource := &infrastructurev1alpha1.SAFCluster{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      resourceName,
-						Namespace: "default",
-					},
-					// TODO(user): Specify other spec details if needed.
-				}
-				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, cluster)).To(Succeed())
+		})
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(safcl), safcl)).To(Succeed())
+		safcl.OwnerReferences = append(safcl.OwnerReferences, metav1.OwnerReference{
+			APIVersion: capv1beta2.GroupVersion.String(),
+			Kind:       "Cluster",
+			Name:       cluster.Name,
+			UID:        cluster.UID,
+		})
+		Expect(k8sClient.Update(ctx, safcl)).To(Succeed())
+		return cluster
+	}
+
+	Context("When cluster has no owner Cluster", func() {
+		It("should add finalizer and wait for owner", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-no-owner", Namespace: "default"},
+				Spec: infrastructurev1alpha1.SAFClusterSpec{
+					ControlPlaneEndpoint: capv1beta2.APIEndpoint{Host: "10.0.0.10", Port: 6443},
+				},
+			}
+			createSAFCluster(safcl)
+
+			for range 3 {
+				reconcileCluster(safcl)
 			}
+			Expect(safcl.Finalizers).To(ContainElement(infrastructurev1alpha1.SAFClusterFinalizer))
+			Expect(safcl.Status.Initialization.Provisioned).To(BeNil())
 		})
+	})
 
-		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
-			resource := &infrastructurev1alpha1.SAFCluster{}
-			err := k8sClient.Get(ctx, typeNamespacedName, resource)
-			Expect(err).NotTo(HaveOccurred())
+	Context("When cluster has owner Cluster", func() {
+		It("should be provisioned with control plane endpoint", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-endpoint", Namespace: "default"},
+				Spec: infrastructurev1alpha1.SAFClusterSpec{
+					ControlPlaneEndpoint: capv1beta2.APIEndpoint{Host: "10.0.0.10", Port: 6443},
+				},
+			}
+			createSAFCluster(safcl)
+			createOwnerCluster(safcl)
 
-			By("Cleanup the specific resource instance SAFCluster")
-			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			for range 3 {
+				reconcileCluster(safcl)
+			}
+			Expect(safcl.Status.Initialization.Provisioned).To(Equal(ptr.To(true)))
+			Expect(conditions.IsTrue(safcl, infrastructurev1alpha1.SAFClusterControlPlaneEndpointAvailableCondition)).To(BeTrue())
+			Expect(conditions.IsTrue(safcl, infrastructurev1alpha1.SAFClusterReadyCondition)).To(BeTrue())
 		})
-		It("should successfully reconcile the resource", func() {
-			By("Reconciling the created resource")
-			controllerReconciler := &safcluster.Reconciler{
-				Client: k8sClient,
+
+		It("should wait for control plane endpoint", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-no-endpoint", Namespace: "default"},
+			}
+			createSAFCluster(safcl)
+			createOwnerCluster(safcl)
+
+			for range 3 {
+				reconcileCluster(safcl)
+			}
+			Expect(safcl.Status.Initialization.Provisioned).To(BeNil())
+			Expect(conditions.GetReason(safcl, infrastructurev1alpha1.SAFClusterControlPlaneEndpointAvailableCondition)).
+				To(Equal(infrastructurev1alpha1.SAFClusterWaitingForControlPlaneEndpointReason))
+			Expect(conditions.IsFalse(safcl, infrastructurev1alpha1.SAFClusterReadyCondition)).To(BeTrue())
+
+			By("setting control plane endpoint")
+			safcl.Spec.ControlPlaneEndpoint = capv1beta2.APIEndpoint{Host: "lb.example.com", Port: 6443}
+			Expect(k8sClient.Update(ctx, safcl)).To(Succeed())
+			reconcileCluster(safcl)
+			Expect(safcl.Status.Initialization.Provisioned).To(Equal(ptr.To(true)))
+		})
+	})
+
+	Context("When Cluster is paused", func() {
+		It("should not provision until Cluster is unpaused", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-paused", Namespace: "default"},
+				Spec: infrastructurev1alpha1.SAFClusterSpec{
+					ControlPlaneEndpoint: capv1beta2.APIEndpoint{Host: "10.0.0.10", Port: 6443},
+					ProvisionJob:         ptr.To(jobTemplate()),
+				},
+			}
+			createSAFCluster(safcl)
+			cluster := createOwnerCluster(safcl)
+			cluster.Spec.Paused = ptr.To(true)
+			Expect(k8sClient.Update(ctx, cluster)).To(Succeed())
+
+			for range 3 {
+				reconcileCluster(safcl)
+			}
+			Expect(conditions.IsTrue(safcl, infrastructurev1alpha1.SAFClusterPausedCondition)).To(BeTrue())
+			Expect(safcl.Finalizers).To(BeEmpty())
+			Expect(safcl.Status.Initialization.Provisioned).To(BeNil())
+			provisionJobKey := types.NamespacedName{Name: safcl.Name + "-cluster-provision", Namespace: "default"}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, provisionJobKey, &batchv1.Job{}))).To(BeTrue())
+
+			By("unpausing the Cluster")
+			cluster.Spec.Paused = ptr.To(false)
+			Expect(k8sClient.Update(ctx, cluster)).To(Succeed())
+			for range 3 {
+				reconcileCluster(safcl)
+			}
+			Expect(conditions.IsFalse(safcl, infrastructurev1alpha1.SAFClusterPausedCondition)).To(BeTrue())
+			Expect(safcl.Finalizers).To(ContainElement(infrastructurev1alpha1.SAFClusterFinalizer))
+			provisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, provisionJobKey, provisionJob)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, provisionJob, client.PropagationPolicy(metav1.DeletePropagationBackground))).
+					To(Succeed())
+			})
+		})
+	})
+
+	Context("When cluster has provision and deprovision jobs", func() {
+		It("should take control plane endpoint from provision job", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-provision-job",
+					Namespace: "default",
+					Labels:    map[string]string{capv1beta2.ClusterNameLabel: "test-provision-job"},
+				},
+				Spec: infrastructurev1alpha1.SAFClusterSpec{
+					ProvisionJob: ptr.To(jobTemplate()),
+				},
+			}
+			createSAFCluster(safcl)
+			createOwnerCluster(safcl)
+
+			for range 3 {
+				reconcileCluster(safcl)
+			}
+			Expect(safcl.Status.Initialization.Provisioned).To(BeNil())
+			Expect(conditions.GetReason(safcl, infrastructurev1alpha1.SAFClusterProvisionJobSucceededCondition)).
+				To(Equal(infrastructurev1alpha1.SAFClusterJobRunningReason))
+
+			provisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: safcl.Name + "-cluster-provision", Namespace: "default",
+			}, provisionJob)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, provisionJob, client.PropagationPolicy(metav1.DeletePropagationBackground))).
+					To(Succeed())
+			})
+			Expect(metav1.IsControlledBy(provisionJob, safcl)).To(BeTrue())
+			Expect(provisionJob.Labels).To(HaveKeyWithValue(infrastructurev1alpha1.SAFClusterNameLabel, safcl.Name))
+			Expect(provisionJob.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "SAF_CLUSTER_NAME", Value: "test-provision-job"},
+			))
+
+			By("completing provision job with result")
+			provisionPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      provisionJob.Name + "-abcde",
+					Namespace: "default",
+					Labels:    map[string]string{batchv1.JobNameLabel: provisionJob.Name},
+				},
+				Spec: *provisionJob.Spec.Template.Spec.DeepCopy(),
+			}
+			Expect(k8sClient.Create(ctx, provisionPod)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, provisionPod)).To(Succeed())
+			})
+			provisionPod.Status.Phase = corev1.PodSucceeded
+			provisionPod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  "main",
+				Image: "main",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: `{"controlPlaneEndpoint":{"host":"10.0.0.100","port":6443}}`,
+				}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, provisionPod)).To(Succeed())
+			completeJob(ctx, provisionJob)
+
+			reconcileCluster(safcl)
+			Expect(safcl.Spec.ControlPlaneEndpoint).To(Equal(capv1beta2.APIEndpoint{Host: "10.0.0.100", Port: 6443}))
+			Expect(safcl.Status.Initialization.Provisioned).To(Equal(ptr.To(true)))
+			Expect(conditions.IsTrue(safcl, infrastructurev1alpha1.SAFClusterProvisionJobSucceededCondition)).To(BeTrue())
+			Expect(conditions.IsTrue(safcl, infrastructurev1alpha1.SAFClusterReadyCondition)).To(BeTrue())
+		})
+
+		It("should take control plane endpoint from provision job through cached client", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-provision-job-cached",
+					Namespace: "default",
+					Labels:    map[string]string{capv1beta2.ClusterNameLabel: "test-provision-job-cached"},
+				},
+				Spec: infrastructurev1alpha1.SAFClusterSpec{
+					ProvisionJob: ptr.To(jobTemplate()),
+				},
+			}
+			createSAFCluster(safcl)
+			createOwnerCluster(safcl)
+
+			By("making a client, that reads from a cache without job pods, like the manager's one")
+			informers, err := cache.New(cfg, cache.Options{
+				Scheme: k8sClient.Scheme(),
+				ByObject: map[client.Object]cache.ByObject{
+					&corev1.Pod{}: {Label: labels.SelectorFromSet(labels.Set{"test-not-a-job-pod": "true"})},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			cacheCtx, cancel := context.WithCancel(ctx)
+			DeferCleanup(cancel)
+			go func() {
+				defer GinkgoRecover()
+				Expect(informers.Start(cacheCtx)).To(Succeed())
+			}()
+			Expect(informers.WaitForCacheSync(cacheCtx)).To(BeTrue())
+			cachedClient, err := client.New(cfg, client.Options{
 				Scheme: k8sClient.Scheme(),
+				Cache:  &client.CacheOptions{Reader: informers},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			controllerReconciler := &safcluster.Reconciler{
+				Client: cachedClient,
+				Scheme: cachedClient.Scheme(),
+				Provisioner: &job.Provisioner{
+					Client:    cachedClient,
+					Scheme:    cachedClient.Scheme(),
+					APIReader: k8sClient,
+				},
 			}
 
-			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
-				NamespacedName: typeNamespacedName,
+			provisionJob := &batchv1.Job{}
+			provisionJobKey := types.NamespacedName{Name: safcl.Name + "-cluster-provision", Namespace: "default"}
+			Eventually(func(g Gomega) {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safcl)})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(k8sClient.Get(ctx, provisionJobKey, provisionJob)).To(Succeed())
+			}).Should(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, provisionJob, client.PropagationPolicy(metav1.DeletePropagationBackground))).
+					To(Succeed())
 			})
+
+			By("completing provision job with result")
+			provisionPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      provisionJob.Name + "-abcde",
+					Namespace: "default",
+					Labels:    provisionJob.Spec.Template.Labels,
+				},
+				Spec: *provisionJob.Spec.Template.Spec.DeepCopy(),
+			}
+			provisionPod.Labels[batchv1.JobNameLabel] = provisionJob.Name
+			Expect(k8sClient.Create(ctx, provisionPod)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, provisionPod)).To(Succeed())
+			})
+			provisionPod.Status.Phase = corev1.PodSucceeded
+			provisionPod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  "main",
+				Image: "main",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: `{"controlPlaneEndpoint":{"host":"10.0.0.101","port":6443}}`,
+				}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, provisionPod)).To(Succeed())
+			completeJob(ctx, provisionJob)
+
+			pods := &corev1.PodList{}
+			Expect(cachedClient.List(ctx, pods, client.MatchingLabels{batchv1.JobNameLabel: provisionJob.Name})).To(Succeed())
+			Expect(pods.Items).To(BeEmpty())
+
+			Eventually(func(g Gomega) {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safcl)})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(safcl), safcl)).To(Succeed())
+				g.Expect(safcl.Status.Initialization.Provisioned).To(Equal(ptr.To(true)))
+			}).Should(Succeed())
+			Expect(safcl.Spec.ControlPlaneEndpoint).To(Equal(capv1beta2.APIEndpoint{Host: "10.0.0.101", Port: 6443}))
+		})
+
+		It("should wait for deprovision job before deletion", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-deprovision-job", Namespace: "default"},
+				Spec: infrastructurev1alpha1.SAFClusterSpec{
+					ControlPlaneEndpoint: capv1beta2.APIEndpoint{Host: "10.0.0.10", Port: 6443},
+					DeprovisionJob:       ptr.To(jobTemplate()),
+				},
+			}
+			createSAFCluster(safcl)
+			createOwnerCluster(safcl)
+			for range 3 {
+				reconcileCluster(safcl)
+			}
+			Expect(safcl.Status.Initialization.Provisioned).To(Equal(ptr.To(true)))
+
+			By("deleting the cluster")
+			Expect(k8sClient.Delete(ctx, safcl)).To(Succeed())
+			reconcileCluster(safcl)
+			Expect(safcl.Finalizers).To(ContainElement(infrastructurev1alpha1.SAFClusterFinalizer))
+			Expect(conditions.GetReason(safcl, infrastructurev1alpha1.SAFClusterDeprovisionJobSucceededCondition)).
+				To(Equal(infrastructurev1alpha1.SAFClusterJobRunningReason))
+
+			deprovisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: safcl.Name + "-cluster-deprovision", Namespace: "default",
+			}, deprovisionJob)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, deprovisionJob, client.PropagationPolicy(metav1.DeletePropagationBackground))).
+					To(Succeed())
+			})
+			Expect(deprovisionJob.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "SAF_CONTROL_PLANE_ENDPOINT_HOST", Value: "10.0.0.10"},
+				corev1.EnvVar{Name: "SAF_CONTROL_PLANE_ENDPOINT_PORT", Value: "6443"},
+			))
+
+			By("completing deprovision job")
+			completeJob(ctx, deprovisionJob)
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safcl)})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(safcl), safcl))).To(BeTrue())
+		})
+	})
+
+	Context("When cluster is moved by clusterctl", func() {
+		It("should restore provisioned state from annotation and not run provision job again", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-moved",
+					Namespace: "default",
+					Annotations: map[string]string{
+						infrastructurev1alpha1.StatusAnnotation: `{"uid":"source-cluster-uid","namespace":"default",` +
+							`"name":"test-moved","status":{"initialization":{"provisioned":true}}}`,
+					},
+				},
+				Spec: infrastructurev1alpha1.SAFClusterSpec{
+					ControlPlaneEndpoint: capv1beta2.APIEndpoint{Host: "10.0.0.10", Port: 6443},
+					ProvisionJob:         ptr.To(jobTemplate()),
+				},
+			}
+			createSAFCluster(safcl)
+			createOwnerCluster(safcl)
+
+			for range 3 {
+				reconcileCluster(safcl)
+			}
+			Expect(safcl.Status.Initialization.Provisioned).To(Equal(ptr.To(true)))
+			Expect(conditions.IsTrue(safcl, infrastructurev1alpha1.SAFClusterProvisionJobSucceededCondition)).To(BeTrue())
+			provisionJobKey := types.NamespacedName{Name: safcl.Name + "-cluster-provision", Namespace: "default"}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, provisionJobKey, &batchv1.Job{}))).To(BeTrue())
+			Expect(safcl.Annotations[infrastructurev1alpha1.StatusAnnotation]).To(ContainSubstring(string(safcl.UID)))
+		})
+
+		It("should not restore status from annotation, copied from another SAFCluster", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-copied",
+					Namespace: "default",
+					Annotations: map[string]string{
+						infrastructurev1alpha1.StatusAnnotation: `{"uid":"source-cluster-uid","namespace":"default",` +
+							`"name":"test-moved","status":{"initialization":{"provisioned":true}}}`,
+					},
+				},
+				Spec: infrastructurev1alpha1.SAFClusterSpec{
+					ControlPlaneEndpoint: capv1beta2.APIEndpoint{Host: "10.0.0.10", Port: 6443},
+					ProvisionJob:         ptr.To(jobTemplate()),
+				},
+			}
+			createSAFCluster(safcl)
+			createOwnerCluster(safcl)
+
+			for range 3 {
+				reconcileCluster(safcl)
+			}
+			Expect(safcl.Status.Initialization.Provisioned).To(BeNil())
+			provisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: safcl.Name + "-cluster-provision", Namespace: "default",
+			}, provisionJob)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, provisionJob, client.PropagationPolicy(metav1.DeletePropagationBackground))).
+					To(Succeed())
+			})
+		})
+	})
+
+	Context("When cluster has failure domains", func() {
+		It("should report failure domains in status", func() {
+			safcl := &infrastructurev1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-failure-domains", Namespace: "default"},
+				Spec: infrastructurev1alpha1.SAFClusterSpec{
+					FailureDomains: []infrastructurev1alpha1.SAFFailureDomain{
+						{Name: "rack-a", ControlPlane: ptr.To(true), Attributes: map[string]string{"site": "one"}},
+						{Name: "rack-b", JobNodeSelector: map[string]string{"rack": "b"}},
+					},
+				},
+			}
+			createSAFCluster(safcl)
+
+			for range 3 {
+				reconcileCluster(safcl)
+			}
+			Expect(safcl.Status.FailureDomains).To(Equal([]capv1beta2.FailureDomain{
+				{Name: "rack-a", ControlPlane: ptr.To(true), Attributes: map[string]string{"site": "one"}},
+				{Name: "rack-b"},
+			}))
 		})
 	})
 })
+
+func jobTemplate() infrastructurev1alpha1.JobTemplate {
+	return infrastructurev1alpha1.JobTemplate{
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "main", Image: "main"}},
+				},
+			},
+		},
+	}
+}
+
+// completeJob marks job as succeeded, envtest has no job controller to do it.
+func completeJob(ctx context.Context, job *batchv1.Job) {
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.CompletionTime = &now
+	job.Status.Succeeded = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue, LastTransitionTime: now},
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: now},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
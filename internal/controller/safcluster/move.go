@@ -0,0 +1,53 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safcluster
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	infrastructurev1alpha1 "github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/movestate"
+)
+
+// restoreStatus restores status of the SAFCluster, moved from another management cluster, from its annotation,
+// so the provision job doesn't run again after the move.
+func restoreStatus(safcl *infrastructurev1alpha1.SAFCluster) error {
+	if !equality.Semantic.DeepEqual(durableStatus(safcl), infrastructurev1alpha1.SAFClusterStatus{}) {
+		return nil
+	}
+	status := infrastructurev1alpha1.SAFClusterStatus{}
+	if ok, err := movestate.Restore(safcl, &status); err != nil || !ok {
+		return err
+	}
+	status.Conditions = safcl.Status.Conditions
+	status.FailureDomains = safcl.Status.FailureDomains
+	safcl.Status = status
+	return nil
+}
+
+// saveStatus keeps status of the SAFCluster in its annotation, as clusterctl move doesn't move status.
+func saveStatus(safcl *infrastructurev1alpha1.SAFCluster) error {
+	return movestate.Save(safcl, durableStatus(safcl))
+}
+
+// durableStatus is the status without conditions and failure domains, that are recalculated on every reconciliation.
+func durableStatus(safcl *infrastructurev1alpha1.SAFCluster) infrastructurev1alpha1.SAFClusterStatus {
+	status := *safcl.Status.DeepCopy()
+	status.Conditions = nil
+	status.FailureDomains = nil
+	return status
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safhost
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/finalizers"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
+)
+
+// Reconciler reconciles a SAFHost object.
+// SAFMachine controller moves claimed hosts through provisioning states, this controller cleans released hosts
+// and makes them available again. Deletion of the host is blocked, until it is Available or Error.
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Cleaner cleans released hosts, e.g. by jobs.
+	Cleaner provisioner.Cleaner
+}
+
+var controllerName = strings.ToLower(v1alpha1.SAFHostKind)
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.SAFHost{}).
+		Owns(&batchv1.Job{}).
+		Named(controllerName).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safhosts,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safhosts/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safhosts/finalizers,verbs=update
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	host := &v1alpha1.SAFHost{}
+	if err := r.Get(ctx, req.NamespacedName, host); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("get saf host: %w", err))
+	}
+
+	if changed, err := finalizers.EnsureFinalizer(ctx, r.Client, host, v1alpha1.SAFHostFinalizer); changed || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pacher, err := patch.NewHelper(host, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("make patcher: %w", err)
+	}
+	if err := restoreStatus(host); err != nil {
+		return ctrl.Result{}, fmt.Errorf("restore status: %w", err)
+	}
+	defer func() {
+		if err := saveStatus(host); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+		opts := []patch.Option{
+			patch.WithOwnedConditions{Conditions: []string{
+				v1alpha1.SAFHostCleanedCondition,
+			}},
+		}
+		if err := pacher.Patch(ctx, host, opts...); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	switch host.Status.State {
+	case "":
+		host.Status.State = v1alpha1.SAFHostAvailable
+		return ctrl.Result{}, nil
+	case v1alpha1.SAFHostError:
+		if host.GetDeletionTimestamp() != nil {
+			return r.reconcileDelete(ctx, host)
+		}
+		return r.retryCleaning(ctx, host)
+	case v1alpha1.SAFHostCleaning:
+		return r.clean(ctx, host)
+	case v1alpha1.SAFHostAvailable:
+		if host.GetDeletionTimestamp() != nil {
+			return r.reconcileDelete(ctx, host)
+		}
+	}
+	// will requeue on host update, when its consumer releases it
+	return ctrl.Result{}, nil
+}
+
+// reconcileDelete removes the finalizer of the host, that is neither claimed nor cleaned,
+// after its failed cleaning job is deleted, as the job may still touch the host.
+func (r *Reconciler) reconcileDelete(ctx context.Context, host *v1alpha1.SAFHost) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "delete")
+
+	if host.Spec.ConsumerRef != nil {
+		// will requeue on host update, when its consumer releases it
+		l.Info("waiting for consumer to release the host", "consumer_name", host.Spec.ConsumerRef.Name)
+		return ctrl.Result{}, nil
+	}
+
+	if host.Status.CleaningJobName != "" {
+		job := &batchv1.Job{}
+		key := types.NamespacedName{Namespace: host.Status.CleaningJobNamespace, Name: host.Status.CleaningJobName}
+		if err := r.Get(ctx, key, job); err == nil {
+			if job.GetDeletionTimestamp() == nil {
+				// pods of the failed job are finished, they don't block its deletion
+				l.Info("delete failed cleaning job", "job_name", key.Name)
+				if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+					return ctrl.Result{}, fmt.Errorf("delete job %s: %w", key.Name, err)
+				}
+			}
+			// will requeue on job deletion
+			l.Info("waiting for cleaning job to be deleted", "job_name", key.Name)
+			return ctrl.Result{}, nil
+		} else if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("get job %s: %w", key.Name, err)
+		}
+	}
+
+	l.Info("host is not used, removing finalizer")
+	controllerutil.RemoveFinalizer(host, v1alpha1.SAFHostFinalizer)
+	return ctrl.Result{}, nil
+}
+
+// clean runs the cleaning job and makes the host available, once it succeeds.
+func (r *Reconciler) clean(ctx context.Context, host *v1alpha1.SAFHost) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "clean")
+
+	if host.Spec.CleaningJob == nil {
+		// cleaning job was removed from the spec, nothing to wait for
+		host.Status.State = v1alpha1.SAFHostAvailable
+		return ctrl.Result{}, nil
+	}
+	if host.Status.CleaningJobName == "" {
+		// name is saved first, so the job is not started twice, if the status is not saved after its creation
+		host.Status.Cleanings++
+		host.Status.CleaningJobName = fmt.Sprintf("%s-cleaning-%d", host.Name, host.Status.Cleanings)
+		return ctrl.Result{}, nil
+	}
+
+	status, err := r.Cleaner.Clean(ctx, host)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("clean host: %w", err)
+	}
+
+	switch status.State {
+	case provisioner.StateSucceeded:
+		l.Info("host cleaned", "job_name", status.Name)
+		host.Status.State = v1alpha1.SAFHostAvailable
+		host.Status.CleaningJobName = ""
+		conditions.Set(host, metav1.Condition{
+			Type:   v1alpha1.SAFHostCleanedCondition,
+			Status: metav1.ConditionTrue,
+			Reason: v1alpha1.SAFHostCleanedReason,
+		})
+	case provisioner.StateFailed:
+		l.Info("cleaning job failed", "job_name", status.Name, "message", status.Message)
+		host.Status.State = v1alpha1.SAFHostError
+		conditions.Set(host, metav1.Condition{
+			Type:    v1alpha1.SAFHostCleanedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.SAFHostCleaningFailedReason,
+			Message: fmt.Sprintf("Job %s failed: %s", status.Name, status.Message),
+		})
+	default:
+		conditions.Set(host, metav1.Condition{
+			Type:    v1alpha1.SAFHostCleanedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.SAFHostCleaningReason,
+			Message: fmt.Sprintf("Job %s is running", status.Name),
+		})
+	}
+	return ctrl.Result{}, nil
+}
+
+// retryCleaning starts cleaning again, after the failed cleaning job is deleted.
+func (r *Reconciler) retryCleaning(ctx context.Context, host *v1alpha1.SAFHost) (ctrl.Result, error) {
+	if host.Status.CleaningJobName != "" {
+		key := types.NamespacedName{Namespace: host.Status.CleaningJobNamespace, Name: host.Status.CleaningJobName}
+		if err := r.Get(ctx, key, &batchv1.Job{}); err == nil {
+			// wait until the job is deleted
+			return ctrl.Result{}, nil
+		} else if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("get job %s: %w", key.Name, err)
+		}
+	}
+
+	logf.FromContext(ctx).Info("failed cleaning job is deleted, clean host again")
+	host.Status.State = v1alpha1.SAFHostCleaning
+	host.Status.CleaningJobName = ""
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safhost_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/controller/safhost"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner/job"
+)
+
+var _ = Describe("SAFHost Controller", func() {
+	ctx := context.Background()
+
+	newReconciler := func() *safhost.Reconciler {
+		return &safhost.Reconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Cleaner: &job.Provisioner{
+				Client:                    k8sClient,
+				Scheme:                    k8sClient.Scheme(),
+				ConnectionConfigEnvPrefix: "SAF_",
+			},
+		}
+	}
+
+	// createHost creates the host, released by its consumer in default namespace, so it waits for cleaning.
+	createHost := func(name string) *v1alpha1.SAFHost {
+		host := &v1alpha1.SAFHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: v1alpha1.SAFHostSpec{
+				ConnectionConfig: map[string]string{"bmc": "10.0.1.5"},
+				CleaningJob: &v1alpha1.JobTemplate{
+					Spec: batchv1.JobSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "wipe", Image: "busybox"}},
+							},
+						},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, host)).To(Succeed())
+		DeferCleanup(func() {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host); errors.IsNotFound(err) {
+				return
+			}
+			host.Finalizers = nil
+			Expect(k8sClient.Update(ctx, host)).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, host))).To(Succeed())
+		})
+		host.Status.State = v1alpha1.SAFHostCleaning
+		host.Status.CleaningJobNamespace = "default"
+		Expect(k8sClient.Status().Update(ctx, host)).To(Succeed())
+		return host
+	}
+
+	reconcileHost := func(r *safhost.Reconciler, host *v1alpha1.SAFHost) {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(host)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host)).To(Succeed())
+	}
+
+	Context("When host is created", func() {
+		It("should make it available", func() {
+			r := newReconciler()
+			host := &v1alpha1.SAFHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-new-host",
+				},
+			}
+			Expect(k8sClient.Create(ctx, host)).To(Succeed())
+
+			for range 2 {
+				reconcileHost(r, host)
+			}
+			Expect(host.Finalizers).To(ContainElement(v1alpha1.SAFHostFinalizer))
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostAvailable))
+
+			By("deleting the available host")
+			Expect(k8sClient.Delete(ctx, host)).To(Succeed())
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(host)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host))).To(BeTrue())
+		})
+	})
+
+	Context("When host is cleaned", func() {
+		It("should make it available after cleaning job succeeded", func() {
+			r := newReconciler()
+			host := createHost("test-cleaned-host")
+
+			for range 3 {
+				reconcileHost(r, host)
+			}
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostCleaning))
+			Expect(host.Status.Cleanings).To(Equal(int32(1)))
+			Expect(host.Status.CleaningJobName).To(Equal(host.Name + "-cleaning-1"))
+			Expect(conditions.GetReason(host, v1alpha1.SAFHostCleanedCondition)).To(Equal(v1alpha1.SAFHostCleaningReason))
+
+			cleaningJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: host.Status.CleaningJobName, Namespace: "default",
+			}, cleaningJob)).To(Succeed())
+			Expect(cleaningJob.Labels).To(HaveKeyWithValue(v1alpha1.SAFHostNameLabel, host.Name))
+			Expect(metav1.IsControlledBy(cleaningJob, host)).To(BeTrue())
+			Expect(cleaningJob.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "SAF_BMC", Value: "10.0.1.5"},
+				corev1.EnvVar{Name: "SAF_HOST_NAME", Value: host.Name},
+			))
+
+			completeJob(ctx, cleaningJob)
+			reconcileHost(r, host)
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostAvailable))
+			Expect(host.Status.CleaningJobName).To(BeEmpty())
+			Expect(conditions.IsTrue(host, v1alpha1.SAFHostCleanedCondition)).To(BeTrue())
+		})
+
+		It("should clean host again after failed cleaning job is deleted", func() {
+			r := newReconciler()
+			host := createHost("test-failed-cleaning-host")
+
+			for range 3 {
+				reconcileHost(r, host)
+			}
+			cleaningJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: host.Status.CleaningJobName, Namespace: "default",
+			}, cleaningJob)).To(Succeed())
+
+			failJob(ctx, cleaningJob)
+			reconcileHost(r, host)
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostError))
+			Expect(conditions.GetReason(host, v1alpha1.SAFHostCleanedCondition)).
+				To(Equal(v1alpha1.SAFHostCleaningFailedReason))
+
+			By("keeping the error, while failed job exists")
+			reconcileHost(r, host)
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostError))
+
+			By("deleting the failed job")
+			// envtest has no garbage collector, so job is deleted without orphaning its pods
+			Expect(k8sClient.Delete(ctx, cleaningJob, client.PropagationPolicy(metav1.DeletePropagationBackground))).To(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(cleaningJob), cleaningJob))
+			}).Should(BeTrue())
+
+			for range 3 {
+				reconcileHost(r, host)
+			}
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostCleaning))
+			Expect(host.Status.Cleanings).To(Equal(int32(2)))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: host.Name + "-cleaning-2", Namespace: "default",
+			}, &batchv1.Job{})).To(Succeed())
+		})
+	})
+
+	Context("When host is deleted", func() {
+		It("should block deletion, until the host is released and its failed cleaning job is deleted", func() {
+			r := newReconciler()
+			host := createHost("test-deleted-host")
+			reconcileHost(r, host)
+			Expect(host.Finalizers).To(ContainElement(v1alpha1.SAFHostFinalizer))
+
+			By("claiming the host, like SAFMachine controller does")
+			host.Spec.ConsumerRef = &corev1.ObjectReference{
+				APIVersion: v1alpha1.GroupVersion.String(),
+				Kind:       v1alpha1.SAFMachineKind,
+				Namespace:  "default",
+				Name:       "test-deleted-host-consumer",
+			}
+			Expect(k8sClient.Update(ctx, host)).To(Succeed())
+			host.Status.State = v1alpha1.SAFHostProvisioned
+			Expect(k8sClient.Status().Update(ctx, host)).To(Succeed())
+
+			Expect(k8sClient.Delete(ctx, host)).To(Succeed())
+			reconcileHost(r, host)
+			Expect(host.Finalizers).To(ContainElement(v1alpha1.SAFHostFinalizer))
+
+			By("releasing the host, so it is cleaned")
+			host.Spec.ConsumerRef = nil
+			Expect(k8sClient.Update(ctx, host)).To(Succeed())
+			host.Status.State = v1alpha1.SAFHostCleaning
+			Expect(k8sClient.Status().Update(ctx, host)).To(Succeed())
+			for range 2 {
+				reconcileHost(r, host)
+			}
+			Expect(host.Finalizers).To(ContainElement(v1alpha1.SAFHostFinalizer))
+			cleaningJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: host.Status.CleaningJobName, Namespace: "default",
+			}, cleaningJob)).To(Succeed())
+
+			By("failing the cleaning job")
+			failJob(ctx, cleaningJob)
+			reconcileHost(r, host)
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostError))
+			reconcileHost(r, host)
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(cleaningJob), cleaningJob))).To(BeTrue())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(host)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host))).To(BeTrue())
+		})
+	})
+})
+
+// completeJob marks job as succeeded, envtest has no job controller to do it.
+func completeJob(ctx context.Context, job *batchv1.Job) {
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.CompletionTime = &now
+	job.Status.Succeeded = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue, LastTransitionTime: now},
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: now},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
+
+// failJob marks job as failed, envtest has no job controller to do it.
+func failJob(ctx context.Context, job *batchv1.Job) {
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.Failed = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue, LastTransitionTime: now, Reason: "BackoffLimitExceeded"},
+		{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: now, Reason: "BackoffLimitExceeded",
+			Message: "Job has reached the specified backoff limit"},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safhost
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/movestate"
+)
+
+// restoreStatus restores status of the SAFHost, moved from another management cluster, from its annotation,
+// so hosts, that failed cleaning, don't become available after the move.
+func restoreStatus(host *v1alpha1.SAFHost) error {
+	if !equality.Semantic.DeepEqual(durableStatus(host), v1alpha1.SAFHostStatus{}) {
+		return nil
+	}
+	status := v1alpha1.SAFHostStatus{}
+	if ok, err := movestate.Restore(host, &status); err != nil || !ok {
+		return err
+	}
+	status.Conditions = host.Status.Conditions
+	host.Status = status
+	return nil
+}
+
+// saveStatus keeps status of the SAFHost in its annotation, as clusterctl move doesn't move status.
+// SAFMachine controller updates state of claimed hosts, this controller saves it on the following reconciliation.
+func saveStatus(host *v1alpha1.SAFHost) error {
+	return movestate.Save(host, durableStatus(host))
+}
+
+// durableStatus is the status without conditions, that are recalculated on every reconciliation.
+func durableStatus(host *v1alpha1.SAFHost) v1alpha1.SAFHostStatus {
+	status := *host.Status.DeepCopy()
+	status.Conditions = nil
+	return status
+}
@@ -18,20 +18,25 @@ package safmachine
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/utils/ptr"
 	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/cluster-api/controllers/clustercache"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/finalizers"
 	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -41,19 +46,40 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
 )
 
 // Reconciler reconciles a SAFMachine object
 type Reconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme       *runtime.Scheme
+	ClusterCache clustercache.ClusterCache
+
+	// Provisioners are backends, selected by SAFMachine's provisioner name.
+	Provisioners map[string]provisioner.Provisioner
+
+	// ProvisionTimeout is the default provisioning timeout for SAFMachines, that don't set their own.
+	// Zero disables the timeout.
+	ProvisionTimeout time.Duration
 }
 
+const (
+	// nodeRequeueAfter is how often workload cluster is checked for the Node, until it is found.
+	nodeRequeueAfter = 20 * time.Second
+
+	defaultRetryInitialDelay = 10 * time.Second
+	defaultRetryMaxDelay     = 5 * time.Minute
+)
+
 var controllerName = strings.ToLower(v1alpha1.SAFMachineKind)
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	l := mgr.GetLogger().WithValues("controller", controllerName, "predicate", "true")
+	clusterToSAFMachines, err := util.ClusterToTypedObjectsMapper(mgr.GetClient(), &v1alpha1.SAFMachineList{}, mgr.GetScheme())
+	if err != nil {
+		return fmt.Errorf("make cluster to saf machines mapper: %w", err)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.SAFMachine{}).
 		Owns(&batchv1.Job{}).
@@ -62,20 +88,59 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 			handler.EnqueueRequestsFromMapFunc(util.MachineToInfrastructureMapFunc(v1alpha1.GroupVersion.WithKind(v1alpha1.SAFMachineKind))),
 			builder.WithPredicates(predicates.ResourceIsChanged(mgr.GetScheme(), l)),
 		).
+		Watches(
+			&capv1beta2.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(clusterToSAFMachines),
+			builder.WithPredicates(predicates.ClusterPausedTransitions(mgr.GetScheme(), l)),
+		).
+		Watches(
+			&capv1beta2.MachinePool{},
+			handler.EnqueueRequestsFromMapFunc(r.machinePoolToSAFMachines),
+			builder.WithPredicates(predicates.ResourceIsChanged(mgr.GetScheme(), l)),
+		).
+		Watches(
+			&v1alpha1.SAFHost{},
+			handler.EnqueueRequestsFromMapFunc(r.safHostToSAFMachines),
+			builder.WithPredicates(predicates.ResourceIsChanged(mgr.GetScheme(), l)),
+		).
 		Named(controllerName).
 		Complete(r)
 }
 
 type scope struct {
-	machine        *capv1beta2.Machine
-	safMachine     *v1alpha1.SAFMachine
-	provisionJob   *batchv1.Job
-	deprovisionJob *batchv1.Job
+	cluster           *capv1beta2.Cluster
+	machine           *capv1beta2.Machine
+	safMachine        *v1alpha1.SAFMachine
+	host              *v1alpha1.SAFHost
+	failureDomain     *v1alpha1.SAFFailureDomain
+	provisioner       provisioner.Provisioner
+	inspectionStatus  *provisioner.Status
+	provisionStatus   *provisioner.Status
+	deprovisionStatus *provisioner.Status
+}
+
+func (s *scope) request(attempt int32) provisioner.Request {
+	return provisioner.Request{
+		SAFMachine:    s.safMachine,
+		Machine:       s.machine,
+		Host:          s.host,
+		FailureDomain: s.failureDomain,
+		Attempt:       attempt,
+	}
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safmachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safmachines/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safmachines/finalizers,verbs=update
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safhosts,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safhosts/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinepools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -85,27 +150,62 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("get saf machine: %w", err))
 	}
 
-	if changed, err := finalizers.EnsureFinalizer(ctx, r.Client, safm, v1alpha1.SAFMachineFinalizer); changed || err != nil {
-		return ctrl.Result{}, err
-	}
-
 	ma, err := util.GetOwnerMachine(ctx, r.Client, safm.ObjectMeta)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("get owner machine: %w", err)
 	}
+	if ma, err = r.withMachinePoolBootstrap(ctx, ma); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	s := &scope{
 		safMachine: safm,
 		machine:    ma,
 	}
+	// SAFMachine may have no owner Machine yet, but it is labeled with the cluster name, e.g. by MachineSet
+	clusterName := safm.Labels[capv1beta2.ClusterNameLabel]
+	if ma != nil {
+		clusterName = ma.Spec.ClusterName
+	}
+	if clusterName != "" {
+		cl, err := util.GetClusterByName(ctx, r.Client, safm.Namespace, clusterName)
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("get owner cluster: %w", err)
+		}
+		s.cluster = cl
+	}
+
+	if isPaused, requeue, err := paused.EnsurePausedCondition(ctx, r.Client, s.cluster, s.safMachine); err != nil || isPaused || requeue {
+		return ctrl.Result{}, err
+	}
+
+	if changed, err := finalizers.EnsureFinalizer(ctx, r.Client, safm, v1alpha1.SAFMachineFinalizer); changed || err != nil {
+		return ctrl.Result{}, err
+	}
+
 	pacher, err := patch.NewHelper(s.safMachine, r.Client)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("make patcher: %w", err)
 	}
+	if err := restoreStatus(s.safMachine); err != nil {
+		return ctrl.Result{}, fmt.Errorf("restore status: %w", err)
+	}
 	defer func() {
 		r.calculateStatus(ctx, s)
+		if err := saveStatus(s.safMachine); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
 		opts := []patch.Option{
-			patch.WithOwnedConditions{},
+			patch.WithOwnedConditions{Conditions: []string{
+				v1alpha1.SAFMachineReadyCondition,
+				v1alpha1.SAFMachineBootstrapDataAvailableCondition,
+				v1alpha1.SAFMachineHostClaimedCondition,
+				v1alpha1.SAFMachineHostInspectedCondition,
+				v1alpha1.SAFMachineProvisionJobSucceededCondition,
+				v1alpha1.SAFMachineProvisionedCondition,
+				v1alpha1.SAFMachineDeprovisionedCondition,
+				v1alpha1.SAFMachinePausedCondition,
+			}},
 		}
 		// Always attempt to patch the object and status after each reconciliation.
 		// Patch ObservedGeneration only if the reconciliation completed successfully
@@ -117,13 +217,27 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Re
 		}
 	}()
 
+	name := s.safMachine.Spec.Provisioner
+	if name == "" {
+		name = v1alpha1.JobProvisioner
+	}
+	if s.provisioner = r.Provisioners[name]; s.provisioner == nil {
+		return ctrl.Result{}, fmt.Errorf("unknown provisioner %q", name)
+	}
+	if err := r.findFailureDomain(ctx, s); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	phases := []reconcileFunc{
 		r.findNode,
-		r.provisionJob,
+		r.claimHost,
+		r.inspect,
+		r.provision,
 	}
 	if s.safMachine.GetDeletionTimestamp() != nil {
-		phases = append(phases, r.deprovisionJob)
+		phases = append(phases, r.deprovision)
 	}
+	phases = append(phases, r.syncHostState)
 
 	return doReconcile(ctx, phases, s)
 }
@@ -153,97 +267,583 @@ func doReconcile(ctx context.Context, phases []reconcileFunc, s *scope) (ctrl.Re
 type reconcileFunc func(context.Context, *scope) (ctrl.Result, error)
 
 func (r *Reconciler) findNode(ctx context.Context, s *scope) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "findNode")
+	ctx = logf.IntoContext(ctx, l)
+
+	if s.safMachine.GetDeletionTimestamp() != nil || s.machine == nil || r.ClusterCache == nil {
+		return ctrl.Result{}, nil
+	}
+	simulator, simulated := s.provisioner.(provisioner.NodeSimulator)
+	if !ptr.Deref(s.safMachine.Status.Initialization.Provisioned, false) ||
+		s.safMachine.Status.NodeName != "" && !simulated {
+		return ctrl.Result{}, nil
+	}
+
+	clusterKey := client.ObjectKey{Namespace: s.machine.Namespace, Name: s.machine.Spec.ClusterName}
+	remoteClient, err := r.ClusterCache.GetClient(ctx, clusterKey)
+	if errors.Is(err, clustercache.ErrClusterNotConnected) {
+		l.Info("workload cluster is not connected yet", "cluster_name", clusterKey.Name)
+		return ctrl.Result{RequeueAfter: nodeRequeueAfter}, nil
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("get workload cluster client: %w", err)
+	}
+
+	if simulated {
+		node, err := simulator.SimulateNode(ctx, remoteClient, s.request(1))
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("simulate node: %w", err)
+		}
+		s.safMachine.Status.NodeName = node.Name
+		// heartbeat of the simulated node is renewed, while the SAFMachine exists
+		return ctrl.Result{RequeueAfter: nodeRequeueAfter}, nil
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := remoteClient.List(ctx, nodes); err != nil {
+		return ctrl.Result{}, fmt.Errorf("list workload cluster nodes: %w", err)
+	}
+
+	node := matchNode(s.safMachine, nodes.Items)
+	if node == nil {
+		l.Info("node is not found in workload cluster", "cluster_name", clusterKey.Name)
+		return ctrl.Result{RequeueAfter: nodeRequeueAfter}, nil
+	}
+
+	// Machine's NodeRef is set by providerID, so give it to the node, if kubelet didn't
+	if node.Spec.ProviderID == "" {
+		l.Info("set provider id to node", "node_name", node.Name)
+		nodePatch := client.MergeFrom(node.DeepCopy())
+		node.Spec.ProviderID = s.safMachine.Spec.ProviderID
+		if err := remoteClient.Patch(ctx, node, nodePatch); err != nil {
+			return ctrl.Result{}, fmt.Errorf("set provider id to node %s: %w", node.Name, err)
+		}
+	}
+
+	s.safMachine.Status.NodeName = node.Name
+	for _, a := range node.Status.Addresses {
+		address := capv1beta2.MachineAddress{
+			Type:    capv1beta2.MachineAddressType(a.Type),
+			Address: a.Address,
+		}
+		if !slices.Contains(s.safMachine.Status.Addresses, address) {
+			s.safMachine.Status.Addresses = append(s.safMachine.Status.Addresses, address)
+		}
+	}
+
 	return ctrl.Result{}, nil
 }
 
-func (r *Reconciler) provisionJob(ctx context.Context, s *scope) (ctrl.Result, error) {
-	// observe state
-	l := logf.FromContext(ctx, "phase", "provisionJob")
-	ctx = logf.IntoContext(ctx, l)
+// matchNode finds the node by provider id or, if kubelet doesn't report it, by hostname from provision result.
+func matchNode(safm *v1alpha1.SAFMachine, nodes []corev1.Node) *corev1.Node {
+	for i := range nodes {
+		if nodes[i].Spec.ProviderID != "" && nodes[i].Spec.ProviderID == safm.Spec.ProviderID {
+			return &nodes[i]
+		}
+	}
 
-	{
-		provJobKey := types.NamespacedName{
-			Name:      fmt.Sprintf("%s-provision", s.safMachine.Name),
-			Namespace: s.safMachine.Namespace,
+	hostnames := []string{}
+	for _, a := range safm.Status.Addresses {
+		if a.Type == capv1beta2.MachineHostName {
+			hostnames = append(hostnames, a.Address)
 		}
-		provJob := &batchv1.Job{}
+	}
+	if len(hostnames) == 0 {
+		return nil
+	}
 
-		if err := r.Get(ctx, provJobKey, provJob); client.IgnoreNotFound(err) != nil {
-			return ctrl.Result{}, err
-		} else if err != nil {
-			l.Info("provision job not found", "provision_job_name", provJobKey.Name)
-			return r.createProvisionJob(ctx, s)
-		} else {
-			s.provisionJob = provJob
+	for i := range nodes {
+		if nodes[i].Spec.ProviderID != "" {
+			// belongs to another machine
+			continue
+		}
+		if slices.Contains(hostnames, nodes[i].Name) {
+			return &nodes[i]
+		}
+		for _, a := range nodes[i].Status.Addresses {
+			if a.Type == corev1.NodeHostName && slices.Contains(hostnames, a.Address) {
+				return &nodes[i]
+			}
 		}
 	}
+	return nil
+}
 
-	// ensure owned
+func (r *Reconciler) provision(ctx context.Context, s *scope) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "provision")
+	ctx = logf.IntoContext(ctx, l)
 
-	return ctrl.Result{}, nil
+	if s.safMachine.Spec.ProviderID == "" {
+		s.safMachine.Spec.ProviderID = providerID(s.safMachine)
+	}
+
+	attempt := lastProvisionAttempt(s.safMachine)
+	if attempt == nil {
+		attempt = &v1alpha1.ProvisionAttempt{Attempt: 1}
+	}
+
+	return r.provisionAttempt(ctx, s, attempt)
 }
 
-func (r *Reconciler) createProvisionJob(ctx context.Context, s *scope) (ctrl.Result, error) {
+// provisionAttempt observes the attempt, the attempt record is the source of truth,
+// as backends may forget finished operations, e.g. jobs are removed by their ttl.
+func (r *Reconciler) provisionAttempt(ctx context.Context, s *scope, attempt *v1alpha1.ProvisionAttempt) (ctrl.Result, error) {
 	l := logf.FromContext(ctx)
-	// don't act, if machine deleting
-	if s.safMachine.GetDeletionTimestamp() != nil {
-		l.Info("safMachine is deleting")
+	req := s.request(attempt.Attempt)
+
+	switch attempt.Result {
+	case "", v1alpha1.ProvisionAttemptRunning, v1alpha1.ProvisionAttemptTimedOut:
+		status, err := s.provisioner.Status(ctx, req, provisioner.OperationProvision)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("get status of provisioning attempt %d: %w", attempt.Attempt, err)
+		}
+		if status.State == provisioner.StateNotStarted && attempt.Result != v1alpha1.ProvisionAttemptTimedOut {
+			if !r.readyToProvision(ctx, s) {
+				return ctrl.Result{}, nil
+			}
+			l.Info("start provisioning", "provisioner", s.safMachine.Spec.Provisioner, "attempt", attempt.Attempt)
+			if status, err = s.provisioner.Provision(ctx, req); err != nil {
+				return ctrl.Result{}, fmt.Errorf("start provisioning attempt %d: %w", attempt.Attempt, err)
+			}
+		}
+		if status.State != provisioner.StateNotStarted {
+			s.provisionStatus = &status
+		}
+	}
+
+	if s.provisionStatus != nil && int(attempt.Attempt) > len(s.safMachine.Status.ProvisionAttempts) {
+		// record started attempt, or adopt the one started without being recorded
+		startTime := s.provisionStatus.StartTime
+		if startTime.IsZero() {
+			startTime = metav1.Now()
+		}
+		s.safMachine.Status.ProvisionAttempts = append(s.safMachine.Status.ProvisionAttempts, v1alpha1.ProvisionAttempt{
+			Attempt:   attempt.Attempt,
+			JobName:   s.provisionStatus.Name,
+			StartTime: startTime,
+			Result:    v1alpha1.ProvisionAttemptRunning,
+		})
+		attempt = lastProvisionAttempt(s.safMachine)
+	}
+
+	if attempt.Result == v1alpha1.ProvisionAttemptTimedOut {
+		return r.cancelProvisioning(ctx, s)
+	}
+
+	now := time.Now()
+	if s.provisionStatus != nil {
+		recordProvisionAttempt(attempt, *s.provisionStatus, now)
+	}
+
+	var timeoutAfter time.Duration
+	if timeout := r.provisionTimeout(s.safMachine); timeout > 0 && len(s.safMachine.Status.ProvisionAttempts) > 0 &&
+		attempt.Result != v1alpha1.ProvisionAttemptSucceeded && s.safMachine.GetDeletionTimestamp() == nil {
+		// time is counted from the first attempt
+		deadline := s.safMachine.Status.ProvisionAttempts[0].StartTime.Add(timeout)
+		if timeoutAfter = deadline.Sub(now); timeoutAfter <= 0 {
+			l.Info("provision timeout exceeded", "provision_job_name", attempt.JobName, "attempt", attempt.Attempt)
+			attempt.Result = v1alpha1.ProvisionAttemptTimedOut
+			attempt.CompletionTime = ptr.To(metav1.NewTime(now))
+			attempt.Message = fmt.Sprintf("Machine was not provisioned within %s", timeout)
+			return r.cancelProvisioning(ctx, s)
+		}
+	}
+
+	switch attempt.Result {
+	case "":
+		// not started yet
 		return ctrl.Result{}, nil
+	case v1alpha1.ProvisionAttemptRunning:
+		// will requeue on job update, when timeout exceeded or when the backend asks to
+		l.Info("provisioning is not finished", "provision_job_name", attempt.JobName)
+		return util.LowestNonZeroResult(
+			ctrl.Result{RequeueAfter: timeoutAfter},
+			ctrl.Result{RequeueAfter: s.provisionStatus.RequeueAfter},
+		), nil
+	case v1alpha1.ProvisionAttemptFailed:
+		if s.safMachine.GetDeletionTimestamp() != nil {
+			return ctrl.Result{}, nil
+		}
+		if retry, after := nextProvisionAttempt(s.safMachine.Spec.RetryPolicy, attempt, now); retry {
+			if after > 0 {
+				l.Info("provisioning failed, waiting before next attempt",
+					"provision_job_name", attempt.JobName, "attempt", attempt.Attempt, "after", after)
+				if timeoutAfter > 0 {
+					after = min(after, timeoutAfter)
+				}
+				return ctrl.Result{RequeueAfter: after}, nil
+			}
+			s.provisionStatus = nil
+			return r.provisionAttempt(ctx, s, &v1alpha1.ProvisionAttempt{Attempt: attempt.Attempt + 1})
+		}
+		return ctrl.Result{}, fmt.Errorf("provision job %s failed on attempt %d: %s",
+			attempt.JobName, attempt.Attempt, attempt.Message)
 	}
 
-	// act -- ensure there is succeeded provision job
-	if s.machine == nil {
-		// will requeue on update
-		l.Info("safMachine's machine is not exsits")
+	if ptr.Deref(s.safMachine.Status.Initialization.Provisioned, false) {
 		return ctrl.Result{}, nil
 	}
 
-	if s.machine.Spec.Bootstrap.DataSecretName == nil {
+	if s.provisionStatus == nil || s.provisionStatus.Result == nil {
+		return ctrl.Result{}, fmt.Errorf("provision job %s is removed before its result was read", attempt.JobName)
+	}
+
+	if s.machine != nil {
+		s.safMachine.Status.FailureDomain = s.machine.Spec.FailureDomain
+	}
+	applyProvisionResult(s.safMachine, *s.provisionStatus.Result)
+	s.safMachine.Status.Initialization.Provisioned = ptr.To(true)
+
+	return ctrl.Result{}, nil
+}
+
+// readyToProvision reports, if provisioning may be started.
+func (r *Reconciler) readyToProvision(ctx context.Context, s *scope) bool {
+	l := logf.FromContext(ctx)
+	switch {
+	case s.safMachine.GetDeletionTimestamp() != nil:
+		l.Info("safMachine is deleting")
+		return false
+	case s.machine == nil:
+		// will requeue on update
+		l.Info("safMachine's machine is not exsits")
+		return false
+	case s.machine.Spec.Bootstrap.DataSecretName == nil:
 		// will requeue on update
 		l.Info("safMachine's bootstrap is not prepared")
+		return false
+	case s.safMachine.Spec.HostSelector != nil && s.host == nil:
+		// will requeue on host update
+		l.Info("safMachine's host is not claimed")
+		return false
+	case s.safMachine.Spec.InspectionJob != nil && s.safMachine.Status.HostFacts == nil:
+		// will requeue on job update
+		l.Info("safMachine's host is not inspected")
+		return false
+	case len(unmetRequirements(s.safMachine.Spec.HostRequirements, s.safMachine.Status.HostFacts)) > 0:
+		// will requeue on spec update
+		l.Info("safMachine's host doesn't meet requirements")
+		return false
+	}
+	return true
+}
+
+// cancelProvisioning cancels unfinished provisioning, after it timed out.
+func (r *Reconciler) cancelProvisioning(ctx context.Context, s *scope) (ctrl.Result, error) {
+	l := logf.FromContext(ctx)
+
+	if s.provisionStatus == nil || s.provisionStatus.State != provisioner.StateRunning || s.provisionStatus.Canceling {
 		return ctrl.Result{}, nil
 	}
 
-	provisionJob := &batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      s.safMachine.Name + "-provision",
-			Namespace: s.safMachine.Namespace,
-		},
-		Spec: *s.safMachine.Spec.ProvisionJob.Spec.DeepCopy(),
+	l.Info("cancel timed out provisioning", "provision_job_name", s.provisionStatus.Name)
+	attempt := lastProvisionAttempt(s.safMachine)
+	return ctrl.Result{}, s.provisioner.Cancel(ctx, s.request(attempt.Attempt), provisioner.OperationProvision)
+}
+
+func (r *Reconciler) provisionTimeout(safm *v1alpha1.SAFMachine) time.Duration {
+	if safm.Spec.ProvisionTimeout != nil {
+		return safm.Spec.ProvisionTimeout.Duration
 	}
+	return r.ProvisionTimeout
+}
 
-	provisionJob.Spec.Template.Spec.Volumes = append(provisionJob.Spec.Template.Spec.Volumes, corev1.Volume{
-		Name: "bootstrap",
-		VolumeSource: corev1.VolumeSource{
-			Secret: &corev1.SecretVolumeSource{
-				SecretName: *s.machine.Spec.Bootstrap.DataSecretName,
-			},
-		},
-	})
-
-	containers := provisionJob.Spec.Template.Spec.Containers
-	for i := range containers {
-		containers[i].VolumeMounts = append(containers[i].VolumeMounts, corev1.VolumeMount{
-			Name:      "bootstrap",
-			ReadOnly:  true,
-			MountPath: "/etc/bootstrap/",
-		})
+func lastProvisionAttempt(safm *v1alpha1.SAFMachine) *v1alpha1.ProvisionAttempt {
+	if len(safm.Status.ProvisionAttempts) == 0 {
+		return nil
 	}
+	return &safm.Status.ProvisionAttempts[len(safm.Status.ProvisionAttempts)-1]
+}
 
-	provisionJob.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
-	provisionJob.Spec.BackoffLimit = ptr.To[int32](1)
+func recordProvisionAttempt(attempt *v1alpha1.ProvisionAttempt, status provisioner.Status, now time.Time) {
+	switch status.State {
+	case provisioner.StateSucceeded:
+		attempt.Result = v1alpha1.ProvisionAttemptSucceeded
+		attempt.Message = ""
+	case provisioner.StateFailed:
+		attempt.Result = v1alpha1.ProvisionAttemptFailed
+		attempt.Message = status.Message
+	default:
+		attempt.Result = v1alpha1.ProvisionAttemptRunning
+		return
+	}
 
-	if err := controllerutil.SetControllerReference(s.safMachine, provisionJob, r.Scheme,
-		controllerutil.WithBlockOwnerDeletion(true)); err != nil {
-		return ctrl.Result{}, fmt.Errorf("set controller ref before create: %w", err)
+	attempt.CompletionTime = status.CompletionTime
+	if attempt.CompletionTime == nil {
+		attempt.CompletionTime = ptr.To(metav1.NewTime(now))
 	}
+}
 
-	return ctrl.Result{}, r.Create(ctx, provisionJob)
+// nextProvisionAttempt reports, if failed attempt should be retried by a new job and how long to wait before it.
+func nextProvisionAttempt(policy *v1alpha1.ProvisionRetryPolicy, failed *v1alpha1.ProvisionAttempt, now time.Time) (bool, time.Duration) {
+	if policy == nil || retryStrategy(policy) != v1alpha1.RecreateJobRetryStrategy {
+		return false, 0
+	}
+	if failed.Attempt >= max(policy.MaxAttempts, 1) {
+		return false, 0
+	}
+
+	delay := defaultRetryInitialDelay
+	if policy.InitialDelay != nil {
+		delay = policy.InitialDelay.Duration
+	}
+	maxDelay := defaultRetryMaxDelay
+	if policy.MaxDelay != nil {
+		maxDelay = policy.MaxDelay.Duration
+	}
+	for range failed.Attempt - 1 {
+		delay *= 2
+		if delay >= maxDelay {
+			break
+		}
+	}
+	delay = min(delay, maxDelay)
+
+	if failed.CompletionTime == nil {
+		return true, delay
+	}
+	return true, max(failed.CompletionTime.Add(delay).Sub(now), 0)
 }
 
-func (r *Reconciler) deprovisionJob(ctx context.Context, s *scope) (ctrl.Result, error) {
+func retryStrategy(policy *v1alpha1.ProvisionRetryPolicy) v1alpha1.ProvisionRetryStrategy {
+	if policy.Strategy == "" {
+		return v1alpha1.RecreateJobRetryStrategy
+	}
+	return policy.Strategy
+}
+
+func applyProvisionResult(safm *v1alpha1.SAFMachine, result v1alpha1.ProvisionResult) {
+	if result.ProviderID != "" {
+		safm.Spec.ProviderID = result.ProviderID
+	}
+	if result.FailureDomain != "" {
+		safm.Status.FailureDomain = result.FailureDomain
+	}
+
+	addresses := []capv1beta2.MachineAddress{}
+	if result.Hostname != "" {
+		addresses = append(addresses, capv1beta2.MachineAddress{
+			Type:    capv1beta2.MachineHostName,
+			Address: result.Hostname,
+		})
+	}
+	addresses = append(addresses, result.Addresses...)
+	if len(addresses) > 0 {
+		safm.Status.Addresses = addresses
+	}
+}
+
+func (r *Reconciler) deprovision(ctx context.Context, s *scope) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "deprovision")
+	ctx = logf.IntoContext(ctx, l)
+
+	if !controllerutil.ContainsFinalizer(s.safMachine, v1alpha1.SAFMachineFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// cancel provisioning, host must not be deprovisioned while provisioning still touches it
+	if s.provisionStatus != nil && s.provisionStatus.State == provisioner.StateRunning {
+		if s.provisionStatus.Canceling {
+			// will requeue on job deletion
+			l.Info("waiting for provisioning to be canceled", "provision_job_name", s.provisionStatus.Name)
+			return ctrl.Result{RequeueAfter: s.provisionStatus.RequeueAfter}, nil
+		}
+		l.Info("cancel running provisioning", "provision_job_name", s.provisionStatus.Name)
+		attempt := lastProvisionAttempt(s.safMachine)
+		return ctrl.Result{}, s.provisioner.Cancel(ctx, s.request(attempt.Attempt), provisioner.OperationProvision)
+	}
+
+	// host was never touched by provisioning, e.g. the SAFMachine waited for a host or bootstrap data,
+	// or its host is gone, so there is nothing to deprovision
+	if lastProvisionAttempt(s.safMachine) == nil || s.safMachine.Spec.HostSelector != nil && s.host == nil {
+		if err := r.releaseHost(ctx, s); err != nil {
+			return ctrl.Result{}, err
+		}
+		l.Info("nothing to deprovision, removing finalizer")
+		controllerutil.RemoveFinalizer(s.safMachine, v1alpha1.SAFMachineFinalizer)
+		return ctrl.Result{}, nil
+	}
+
+	status, err := s.provisioner.Deprovision(ctx, s.request(1))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("start deprovisioning: %w", err)
+	}
+	s.deprovisionStatus = &status
+
+	switch status.State {
+	case provisioner.StateFailed:
+		return ctrl.Result{}, fmt.Errorf("deprovision job %s failed: %s", status.Name, status.Message)
+	case provisioner.StateSucceeded:
+	default:
+		// will requeue on job update or when the backend asks to
+		l.Info("deprovisioning is not finished", "deprovision_job_name", status.Name)
+		return ctrl.Result{RequeueAfter: status.RequeueAfter}, nil
+	}
+
+	if err := r.releaseHost(ctx, s); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	l.Info("deprovisioning succeeded, removing finalizer", "deprovision_job_name", status.Name)
+	controllerutil.RemoveFinalizer(s.safMachine, v1alpha1.SAFMachineFinalizer)
+
 	return ctrl.Result{}, nil
 }
 
+func providerID(safm *v1alpha1.SAFMachine) string {
+	return fmt.Sprintf("saf://%s/%s", safm.Namespace, safm.Name)
+}
+
 func (r *Reconciler) calculateStatus(ctx context.Context, s *scope) {
+	l := logf.FromContext(ctx)
+	safm := s.safMachine
+
+	setCondition := func(conditionType string, status metav1.ConditionStatus, reason, message string) {
+		conditions.Set(safm, metav1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: safm.Generation,
+		})
+	}
+
+	switch {
+	case s.machine == nil:
+		setCondition(v1alpha1.SAFMachineBootstrapDataAvailableCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachineWaitingForMachineReason, "Waiting for Machine to set owner reference")
+	case s.machine.Spec.Bootstrap.DataSecretName == nil:
+		setCondition(v1alpha1.SAFMachineBootstrapDataAvailableCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachineWaitingForBootstrapDataReason, "Waiting for Machine's bootstrap data secret")
+	default:
+		setCondition(v1alpha1.SAFMachineBootstrapDataAvailableCondition, metav1.ConditionTrue,
+			v1alpha1.SAFMachineBootstrapDataAvailableReason, "")
+	}
+
+	switch {
+	case safm.Spec.HostSelector == nil && safm.Status.HostRef == nil:
+	case s.host != nil:
+		setCondition(v1alpha1.SAFMachineHostClaimedCondition, metav1.ConditionTrue,
+			v1alpha1.SAFMachineHostClaimedReason, "")
+	case safm.Status.HostRef == nil:
+		message := "Waiting for an available SAFHost matching hostSelector"
+		if s.failureDomain != nil {
+			message += fmt.Sprintf(" in failure domain %s", s.failureDomain.Name)
+		}
+		setCondition(v1alpha1.SAFMachineHostClaimedCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachineWaitingForHostReason, message)
+	default:
+		setCondition(v1alpha1.SAFMachineHostClaimedCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachineHostNotFoundReason,
+			fmt.Sprintf("SAFHost %s is not found or is claimed by another SAFMachine", safm.Status.HostRef.Name))
+	}
+
+	provisioned := ptr.Deref(safm.Status.Initialization.Provisioned, false)
+	if safm.Spec.InspectionJob != nil && (safm.Status.HostFacts != nil || !provisioned) {
+		status, reason, message := jobConditionFields(s.inspectionStatus, safm.Status.HostFacts != nil)
+		switch reason {
+		case v1alpha1.SAFMachineJobSucceededReason:
+			reason = v1alpha1.SAFMachineHostInspectedReason
+			if unmet := unmetRequirements(safm.Spec.HostRequirements, safm.Status.HostFacts); len(unmet) > 0 {
+				status = metav1.ConditionFalse
+				reason = v1alpha1.SAFMachineRequirementsNotMetReason
+				message = "Host doesn't meet requirements: " + strings.Join(unmet, "; ")
+			}
+		case v1alpha1.SAFMachineJobFailedReason:
+			reason = v1alpha1.SAFMachineInspectionFailedReason
+		default:
+			reason = v1alpha1.SAFMachineInspectingReason
+		}
+		setCondition(v1alpha1.SAFMachineHostInspectedCondition, status, reason, message)
+	}
+
+	provisionJobStatus, provisionJobReason, provisionJobMessage := jobConditionFields(s.provisionStatus, provisioned)
+	if attempt := lastProvisionAttempt(safm); s.provisionStatus == nil && !provisioned && attempt != nil &&
+		attempt.Result == v1alpha1.ProvisionAttemptFailed {
+		// failed job is already removed
+		provisionJobReason = v1alpha1.SAFMachineJobFailedReason
+		provisionJobMessage = fmt.Sprintf("Job %s failed: %s", attempt.JobName, attempt.Message)
+	}
+	if attempt := lastProvisionAttempt(safm); provisionJobReason == v1alpha1.SAFMachineJobFailedReason && attempt != nil {
+		if retry, _ := nextProvisionAttempt(safm.Spec.RetryPolicy, attempt, time.Now()); retry {
+			provisionJobReason = v1alpha1.SAFMachineJobRetryingReason
+			provisionJobMessage = fmt.Sprintf("%s, retrying after attempt %d of %d",
+				provisionJobMessage, attempt.Attempt, safm.Spec.RetryPolicy.MaxAttempts)
+		}
+	}
+	timedOut := false
+	if attempt := lastProvisionAttempt(safm); !provisioned && attempt != nil &&
+		attempt.Result == v1alpha1.ProvisionAttemptTimedOut {
+		timedOut = true
+		provisionJobStatus = metav1.ConditionFalse
+		provisionJobReason = v1alpha1.SAFMachineJobFailedReason
+		provisionJobMessage = fmt.Sprintf("Job %s is canceled: %s", attempt.JobName, attempt.Message)
+	}
+	setCondition(v1alpha1.SAFMachineProvisionJobSucceededCondition, provisionJobStatus, provisionJobReason, provisionJobMessage)
+
+	switch {
+	case provisioned:
+		setCondition(v1alpha1.SAFMachineProvisionedCondition, metav1.ConditionTrue,
+			v1alpha1.SAFMachineProvisionedReason, "")
+	case timedOut:
+		setCondition(v1alpha1.SAFMachineProvisionedCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachineProvisionTimedOutReason, provisionJobMessage)
+	case provisionJobReason == v1alpha1.SAFMachineJobFailedReason:
+		setCondition(v1alpha1.SAFMachineProvisionedCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachineProvisioningFailedReason, provisionJobMessage)
+	default:
+		setCondition(v1alpha1.SAFMachineProvisionedCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachineProvisioningReason, provisionJobMessage)
+	}
+
+	if safm.GetDeletionTimestamp() != nil {
+		deprovisioned := !controllerutil.ContainsFinalizer(safm, v1alpha1.SAFMachineFinalizer)
+		status, reason, message := jobConditionFields(s.deprovisionStatus, deprovisioned)
+		switch reason {
+		case v1alpha1.SAFMachineJobSucceededReason:
+			reason = v1alpha1.SAFMachineDeprovisionedReason
+		case v1alpha1.SAFMachineJobFailedReason:
+			reason = v1alpha1.SAFMachineDeprovisioningFailedReason
+		default:
+			reason = v1alpha1.SAFMachineDeprovisioningReason
+		}
+		setCondition(v1alpha1.SAFMachineDeprovisionedCondition, status, reason, message)
+	}
+
+	if err := conditions.SetSummaryCondition(safm, safm, v1alpha1.SAFMachineReadyCondition,
+		conditions.ForConditionTypes{
+			v1alpha1.SAFMachineBootstrapDataAvailableCondition,
+			v1alpha1.SAFMachineHostClaimedCondition,
+			v1alpha1.SAFMachineHostInspectedCondition,
+			v1alpha1.SAFMachineProvisionJobSucceededCondition,
+			v1alpha1.SAFMachineProvisionedCondition,
+			v1alpha1.SAFMachineDeprovisionedCondition,
+		},
+		conditions.IgnoreTypesIfMissing{
+			v1alpha1.SAFMachineHostClaimedCondition,
+			v1alpha1.SAFMachineHostInspectedCondition,
+			v1alpha1.SAFMachineDeprovisionedCondition,
+		},
+	); err != nil {
+		l.Error(err, "set ready condition")
+	}
+}
+
+// jobConditionFields describes status of the operation as condition fields. Succeeded job may be already removed,
+// so done reports, that the operation succeeded some time ago.
+func jobConditionFields(status *provisioner.Status, done bool) (metav1.ConditionStatus, string, string) {
+	if done {
+		return metav1.ConditionTrue, v1alpha1.SAFMachineJobSucceededReason, ""
+	}
+	if status == nil || status.State == provisioner.StateNotStarted {
+		return metav1.ConditionFalse, v1alpha1.SAFMachineJobNotCreatedReason, "Job is not created yet"
+	}
+
+	switch status.State {
+	case provisioner.StateRunning:
+		return metav1.ConditionFalse, v1alpha1.SAFMachineJobRunningReason, fmt.Sprintf("Job %s is running", status.Name)
+	case provisioner.StateFailed:
+		return metav1.ConditionFalse, v1alpha1.SAFMachineJobFailedReason,
+			fmt.Sprintf("Job %s failed: %s", status.Name, status.Message)
+	}
+	return metav1.ConditionTrue, v1alpha1.SAFMachineJobSucceededReason, ""
 }
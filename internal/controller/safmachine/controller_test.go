@@ -18,18 +18,30 @@ package safmachine_test
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/cluster-api/controllers/clustercache"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
 	"github.com/GoodCoffeeLover/saf-api/internal/controller/safmachine"
+	"github.com/GoodCoffeeLover/saf-api/internal/movestate"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner/job"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner/noop"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner/simulate"
 )
 
 var _ = Describe("SAFMachine Controller", func() {
@@ -101,8 +113,9 @@ var _ = Describe("SAFMachine Controller", func() {
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := &safmachine.Reconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
@@ -113,4 +126,1724 @@ var _ = Describe("SAFMachine Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When provision job succeeded", func() {
+		const resourceName = "test-provisioned-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		AfterEach(func() {
+			resource := &v1alpha1.SAFMachine{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Finalizers = nil
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should report machine as provisioned", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			By("creating the custom resource for the Kind SAFMachine")
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+			By("creating succeeded provision job")
+			provisionJob := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-provision",
+					Namespace: "default",
+				},
+				Spec: jobTemplate().Spec,
+			}
+			provisionJob.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+			Expect(k8sClient.Create(ctx, provisionJob)).To(Succeed())
+			completeJob(ctx, provisionJob)
+
+			By("creating succeeded provision pod with result")
+			provisionPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-provision-abcde",
+					Namespace: "default",
+					Labels:    map[string]string{batchv1.JobNameLabel: provisionJob.Name},
+				},
+				Spec: *provisionJob.Spec.Template.Spec.DeepCopy(),
+			}
+			Expect(k8sClient.Create(ctx, provisionPod)).To(Succeed())
+			provisionPod.Status.Phase = corev1.PodSucceeded
+			provisionPod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  "main",
+				Image: "main",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: `{"providerID":"saf://host-1","hostname":"host-1",` +
+						`"addresses":[{"type":"InternalIP","address":"10.0.0.1"}]}`,
+				}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, provisionPod)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, provisionPod)).To(Succeed())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Spec.ProviderID).To(Equal("saf://host-1"))
+			Expect(resource.Status.Initialization.Provisioned).To(HaveValue(BeTrue()))
+			Expect(conditions.IsTrue(resource, v1alpha1.SAFMachineProvisionJobSucceededCondition)).To(BeTrue())
+			Expect(conditions.IsTrue(resource, v1alpha1.SAFMachineProvisionedCondition)).To(BeTrue())
+			Expect(conditions.IsFalse(resource, v1alpha1.SAFMachinePausedCondition)).To(BeTrue())
+			Expect(resource.Status.Addresses).To(ConsistOf(
+				capv1beta2.MachineAddress{Type: capv1beta2.MachineHostName, Address: "host-1"},
+				capv1beta2.MachineAddress{Type: capv1beta2.MachineInternalIP, Address: "10.0.0.1"},
+			))
+		})
+	})
+
+	Context("When machine node joined workload cluster", func() {
+		const (
+			resourceName = "test-node-resource"
+			nodeName     = "test-node"
+		)
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		clusterKey := types.NamespacedName{
+			Name:      "test-cluster",
+			Namespace: "default",
+		}
+
+		It("should find the node by hostname and give it provider id", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				ClusterCache: clustercache.NewFakeClusterCache(k8sClient, clusterKey),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			By("creating owner machine")
+			machine := newMachine(resourceName, clusterKey.Name)
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			By("creating provisioned SAFMachine")
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ProviderID:     "saf://default/" + resourceName,
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Finalizers = nil
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+			resource.Status.Initialization.Provisioned = ptr.To(true)
+			resource.Status.Addresses = []capv1beta2.MachineAddress{
+				{Type: capv1beta2.MachineHostName, Address: nodeName},
+			}
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+
+			By("creating workload cluster node without provider id")
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			}
+			Expect(k8sClient.Create(ctx, node)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, node)).To(Succeed())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.NodeName).To(Equal(nodeName))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+			Expect(node.Spec.ProviderID).To(Equal(resource.Spec.ProviderID))
+		})
+	})
+
+	Context("When machine has connection config", func() {
+		const resourceName = "test-connection-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should expose connection config to provision job as env", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{
+					ConnectionConfigEnvPrefix: job.DefaultConnectionConfigEnvPrefix,
+				}),
+			}
+
+			By("creating owner machine")
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			By("creating connection config secret")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-credentials",
+					Namespace: "default",
+				},
+				StringData: map[string]string{
+					"password": "secret",
+					"ssh-key":  "key",
+				},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			})
+
+			By("creating SAFMachine with connection config")
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ConnectionConfig: map[string]string{
+						"host":     "10.0.0.1",
+						"ssh-user": "root",
+					},
+					ConnectionConfigFrom: []v1alpha1.ConnectionConfigSource{
+						{SecretRef: &corev1.LocalObjectReference{Name: secret.Name}},
+						{
+							Name: "bmc-password",
+							ValueFrom: &v1alpha1.ConnectionConfigValueSource{
+								SecretKeyRef: corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+									Key:                  "password",
+								},
+							},
+						},
+						{
+							Name: "bmc-user",
+							ValueFrom: &v1alpha1.ConnectionConfigValueSource{
+								SecretKeyRef: corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: resourceName + "-missing"},
+									Key:                  "user",
+									Optional:             ptr.To(true),
+								},
+							},
+						},
+						{
+							Name: "bmc-token",
+							ValueFrom: &v1alpha1.ConnectionConfigValueSource{
+								SecretKeyRef: corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+									Key:                  "token",
+									Optional:             ptr.To(true),
+								},
+							},
+						},
+					},
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Finalizers = nil
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			provisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: resourceName + "-provision", Namespace: "default",
+			}, provisionJob)).To(Succeed())
+			Expect(provisionJob.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "SAF_HOST", Value: "10.0.0.1"},
+				corev1.EnvVar{Name: "SAF_SSH_USER", Value: "root"},
+				corev1.EnvVar{Name: "SAF_PROVIDER_ID", Value: "saf://default/" + resourceName},
+				corev1.EnvVar{Name: "SAF_SSH_KEY", ValueFrom: secretKeyRef(secret.Name, "ssh-key")},
+				corev1.EnvVar{Name: "SAF_PASSWORD", ValueFrom: secretKeyRef(secret.Name, "password")},
+				corev1.EnvVar{Name: "SAF_BMC_PASSWORD", ValueFrom: secretKeyRef(secret.Name, "password")},
+			))
+			Expect(provisionJob.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(
+				HaveField("Name", BeElementOf("SAF_BMC_USER", "SAF_BMC_TOKEN")),
+			))
+		})
+	})
+
+	Context("When machine is provisioned over ssh", func() {
+		const resourceName = "test-ssh-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should generate provision job, that runs ssh", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{SSHImage: "ssh-image"}),
+			}
+
+			By("creating owner machine")
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			By("creating SAFMachine with ssh provisioner")
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					SSH: &v1alpha1.SSHProvisioner{
+						Host: "10.0.0.1",
+						KeySecretRef: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: resourceName + "-ssh"},
+							Key:                  "ssh-privatekey",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Finalizers = nil
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+			Expect(resource.Spec.SSH.Port).To(BeEquivalentTo(22))
+			Expect(resource.Spec.SSH.User).To(Equal("root"))
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			provisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: resourceName + "-provision", Namespace: "default",
+			}, provisionJob)).To(Succeed())
+			podSpec := provisionJob.Spec.Template.Spec
+			Expect(podSpec.Containers).To(HaveLen(1))
+			Expect(podSpec.Containers[0].Image).To(Equal("ssh-image"))
+			Expect(podSpec.Containers[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "SAF_SSH_HOST", Value: "10.0.0.1"},
+				corev1.EnvVar{Name: "SAF_SSH_PORT", Value: "22"},
+				corev1.EnvVar{Name: "SAF_SSH_USER", Value: "root"},
+				corev1.EnvVar{Name: "SAF_SSH_UPLOAD_BOOTSTRAP_DATA", Value: "true"},
+			))
+			Expect(podSpec.Volumes).To(HaveLen(2))
+			Expect(podSpec.Volumes[0].Secret.SecretName).To(Equal(resourceName + "-ssh"))
+			Expect(podSpec.Volumes[1].Name).To(Equal(v1alpha1.BootstrapVolumeName))
+		})
+	})
+
+	Context("When provision job failed with retry policy", func() {
+		const resourceName = "test-retry-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should recreate provision job until attempts are exhausted", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			By("creating owner machine")
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			By("creating SAFMachine with retry policy")
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+					RetryPolicy: &v1alpha1.ProvisionRetryPolicy{
+						MaxAttempts:  2,
+						Strategy:     v1alpha1.RecreateJobRetryStrategy,
+						InitialDelay: &metav1.Duration{},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Finalizers = nil
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("failing the first provision job")
+			firstJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: resourceName + "-provision", Namespace: "default",
+			}, firstJob)).To(Succeed())
+			Expect(firstJob.Spec.BackoffLimit).To(HaveValue(BeZero()))
+			failJob(ctx, firstJob)
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			secondJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: resourceName + "-provision-2", Namespace: "default",
+			}, secondJob)).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ProvisionAttempts).To(HaveLen(2))
+			Expect(resource.Status.ProvisionAttempts[0].Result).To(Equal(v1alpha1.ProvisionAttemptFailed))
+			Expect(resource.Status.ProvisionAttempts[1].JobName).To(Equal(secondJob.Name))
+
+			By("failing the last provision job")
+			failJob(ctx, secondJob)
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).To(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ProvisionAttempts).To(HaveLen(2))
+			Expect(resource.Status.ProvisionAttempts[1].Result).To(Equal(v1alpha1.ProvisionAttemptFailed))
+			Expect(conditions.GetReason(resource, v1alpha1.SAFMachineProvisionJobSucceededCondition)).
+				To(Equal(v1alpha1.SAFMachineJobFailedReason))
+		})
+	})
+
+	Context("When provisioning timed out", func() {
+		const resourceName = "test-timeout-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should cancel provision job and mark machine as failed", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				ProvisionTimeout: time.Hour,
+				Provisioners:     provisioners(&job.Provisioner{}),
+			}
+
+			By("creating owner machine")
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			By("creating SAFMachine with provision timeout")
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ProvisionJob:     jobTemplate(),
+					DeprovisionJob:   jobTemplate(),
+					ProvisionTimeout: &metav1.Duration{Duration: time.Millisecond},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Finalizers = nil
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+
+			for range 4 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			provisionJob := &batchv1.Job{}
+			err := k8sClient.Get(ctx, types.NamespacedName{
+				Name: resourceName + "-provision", Namespace: "default",
+			}, provisionJob)
+			if err == nil {
+				Expect(provisionJob.DeletionTimestamp).NotTo(BeNil())
+			} else {
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ProvisionAttempts).To(HaveLen(1))
+			Expect(resource.Status.ProvisionAttempts[0].Result).To(Equal(v1alpha1.ProvisionAttemptTimedOut))
+			Expect(conditions.GetReason(resource, v1alpha1.SAFMachineProvisionedCondition)).
+				To(Equal(v1alpha1.SAFMachineProvisionTimedOutReason))
+			Expect(conditions.IsFalse(resource, v1alpha1.SAFMachineReadyCondition)).To(BeTrue())
+		})
+	})
+
+	Context("When machine uses noop provisioner", func() {
+		const resourceName = "test-noop-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should provision and deprovision without jobs", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			By("creating owner machine")
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			By("creating SAFMachine with noop provisioner")
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					Provisioner: v1alpha1.NoopProvisioner,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Initialization.Provisioned).To(HaveValue(BeTrue()))
+			Expect(resource.Status.ProvisionAttempts).To(HaveLen(1))
+			Expect(resource.Status.ProvisionAttempts[0].Result).To(Equal(v1alpha1.ProvisionAttemptSucceeded))
+
+			jobs := &batchv1.JobList{}
+			Expect(k8sClient.List(ctx, jobs, client.InNamespace("default"),
+				client.MatchingLabels{v1alpha1.SAFMachineNameLabel: resourceName})).To(Succeed())
+			Expect(jobs.Items).To(BeEmpty())
+
+			By("deleting the resource")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should fail on unknown provisioner", func() {
+			typeNamespacedName := types.NamespacedName{
+				Name:      "test-unknown-provisioner-resource",
+				Namespace: "default",
+			}
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      typeNamespacedName.Name,
+					Namespace: typeNamespacedName.Namespace,
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					Provisioner: "Unknown",
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Finalizers = nil
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+
+			var err error
+			for range 3 {
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			}
+			Expect(err).To(MatchError(ContainSubstring(`unknown provisioner "Unknown"`)))
+		})
+	})
+
+	Context("When Cluster is paused", func() {
+		const resourceName = "test-paused-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should not provision until Cluster is unpaused", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			By("creating paused cluster and owner machine")
+			cluster := &capv1beta2.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       capv1beta2.ClusterSpec{Paused: ptr.To(true)},
+			}
+			Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, cluster)).To(Succeed())
+			})
+			machine := newMachine(resourceName, cluster.Name)
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					Provisioner: v1alpha1.NoopProvisioner,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(conditions.IsTrue(resource, v1alpha1.SAFMachinePausedCondition)).To(BeTrue())
+			Expect(resource.Finalizers).To(BeEmpty())
+			Expect(resource.Status.Initialization.Provisioned).To(BeNil())
+			Expect(resource.Status.ProvisionAttempts).To(BeEmpty())
+
+			By("unpausing the cluster")
+			cluster.Spec.Paused = ptr.To(false)
+			Expect(k8sClient.Update(ctx, cluster)).To(Succeed())
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(conditions.IsFalse(resource, v1alpha1.SAFMachinePausedCondition)).To(BeTrue())
+			Expect(resource.Finalizers).To(ContainElement(v1alpha1.SAFMachineFinalizer))
+			Expect(resource.Status.Initialization.Provisioned).To(HaveValue(BeTrue()))
+		})
+	})
+
+	Context("When machine is an instance of SAFMachinePool", func() {
+		const resourceName = "test-pool-instance"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should provision with bootstrap data of the MachinePool", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			By("creating machine pool without bootstrap data and its machine")
+			machinePool := &capv1beta2.MachinePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+					Labels:    map[string]string{capv1beta2.ClusterNameLabel: resourceName},
+				},
+				Spec: capv1beta2.MachinePoolSpec{
+					ClusterName: resourceName,
+					Template: capv1beta2.MachineTemplateSpec{
+						Spec: capv1beta2.MachineSpec{
+							ClusterName: resourceName,
+							Bootstrap: capv1beta2.Bootstrap{
+								ConfigRef: capv1beta2.ContractVersionedObjectReference{
+									APIGroup: "bootstrap.cluster.x-k8s.io",
+									Kind:     "KubeadmConfig",
+									Name:     resourceName,
+								},
+							},
+							InfrastructureRef: capv1beta2.ContractVersionedObjectReference{
+								APIGroup: v1alpha1.GroupVersion.Group,
+								Kind:     v1alpha1.SAFMachinePoolKind,
+								Name:     resourceName,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, machinePool)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machinePool)).To(Succeed())
+			})
+			// MachinePool creates machines without bootstrap data
+			machine := newMachine(resourceName, resourceName)
+			machine.Labels = map[string]string{
+				capv1beta2.ClusterNameLabel:     resourceName,
+				capv1beta2.MachinePoolNameLabel: machinePool.Name,
+			}
+			machine.Spec.Bootstrap.DataSecretName = ptr.To("")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					Labels:          machine.Labels,
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Finalizers = nil
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(conditions.GetReason(resource, v1alpha1.SAFMachineBootstrapDataAvailableCondition)).
+				To(Equal(v1alpha1.SAFMachineWaitingForBootstrapDataReason))
+			provisionJob := &batchv1.Job{}
+			provisionJobKey := types.NamespacedName{Name: resourceName + "-provision", Namespace: "default"}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, provisionJobKey, provisionJob))).To(BeTrue())
+
+			By("setting bootstrap data of the machine pool")
+			machinePool.Spec.Template.Spec.Bootstrap.DataSecretName = ptr.To(resourceName + "-pool-bootstrap")
+			Expect(k8sClient.Update(ctx, machinePool)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, provisionJobKey, provisionJob)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, provisionJob,
+					client.PropagationPolicy(metav1.DeletePropagationBackground))).To(Succeed())
+			})
+			Expect(provisionJob.Spec.Template.Spec.Volumes).To(ContainElement(SatisfyAll(
+				HaveField("Name", v1alpha1.BootstrapVolumeName),
+				HaveField("VolumeSource.Secret.SecretName", resourceName+"-pool-bootstrap"),
+			)))
+		})
+	})
+
+	Context("When machine is moved by clusterctl", func() {
+		const resourceName = "test-moved-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should restore provisioned state from annotation and not provision again", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			By("creating host claimed by the SAFMachine in the source cluster")
+			host := &v1alpha1.SAFHost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-host"},
+				Spec: v1alpha1.SAFHostSpec{
+					ConsumerRef: &corev1.ObjectReference{
+						APIVersion: v1alpha1.GroupVersion.String(),
+						Kind:       v1alpha1.SAFMachineKind,
+						Namespace:  "default",
+						Name:       resourceName,
+						UID:        "source-cluster-uid",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, host)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, host)).To(Succeed())
+			})
+
+			By("creating SAFMachine without status, like clusterctl move does")
+			source := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default", UID: "source-cluster-uid"},
+			}
+			Expect(movestate.Save(source, v1alpha1.SAFMachineStatus{
+				Initialization: v1alpha1.SAFMachineInitializationStatus{Provisioned: ptr.To(true)},
+				Addresses:      []capv1beta2.MachineAddress{{Type: capv1beta2.MachineInternalIP, Address: "10.0.0.1"}},
+				HostRef:        &corev1.LocalObjectReference{Name: host.Name},
+				ProvisionAttempts: []v1alpha1.ProvisionAttempt{{
+					Attempt:   1,
+					JobName:   resourceName + "-provision",
+					StartTime: metav1.Now(),
+					Result:    v1alpha1.ProvisionAttemptSucceeded,
+				}},
+			})).To(Succeed())
+			safm := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+					Annotations:     source.Annotations,
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ProviderID:     "saf://default/" + resourceName,
+					HostSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"pool": resourceName}},
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, safm)).To(Succeed())
+				safm.Finalizers = nil
+				Expect(k8sClient.Update(ctx, safm)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, safm)).To(Succeed())
+			Expect(safm.Status.Initialization.Provisioned).To(HaveValue(BeTrue()))
+			Expect(safm.Status.Addresses).To(ConsistOf(
+				capv1beta2.MachineAddress{Type: capv1beta2.MachineInternalIP, Address: "10.0.0.1"},
+			))
+			Expect(safm.Status.ProvisionAttempts).To(HaveLen(1))
+			Expect(conditions.IsTrue(safm, v1alpha1.SAFMachineProvisionedCondition)).To(BeTrue())
+			provisionJobKey := types.NamespacedName{Name: resourceName + "-provision", Namespace: "default"}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, provisionJobKey, &batchv1.Job{}))).To(BeTrue())
+
+			By("checking host is adopted by the moved SAFMachine")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host)).To(Succeed())
+			Expect(host.Spec.ConsumerRef.UID).To(Equal(safm.UID))
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostProvisioned))
+		})
+
+		It("should not restore status from annotation, copied from another SAFMachine", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+			copiedNamespacedName := types.NamespacedName{Name: "test-copied-resource", Namespace: "default"}
+
+			source := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default", UID: "source-cluster-uid"},
+			}
+			Expect(movestate.Save(source, v1alpha1.SAFMachineStatus{
+				Initialization: v1alpha1.SAFMachineInitializationStatus{Provisioned: ptr.To(true)},
+			})).To(Succeed())
+			safm := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        copiedNamespacedName.Name,
+					Namespace:   copiedNamespacedName.Namespace,
+					Annotations: source.Annotations,
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: copiedNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: copiedNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, copiedNamespacedName, safm)).To(Succeed())
+			Expect(safm.Status.Initialization.Provisioned).To(BeNil())
+			Expect(safm.Annotations[v1alpha1.StatusAnnotation]).To(ContainSubstring(string(safm.UID)))
+		})
+	})
+
+	Context("When machine uses simulate provisioner", func() {
+		ctx := context.Background()
+
+		newSimulated := func(name string, machine *capv1beta2.Machine, failurePercent int32) *v1alpha1.SAFMachine {
+			return &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            name,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					Provisioner: v1alpha1.SimulateProvisioner,
+					Simulate: &v1alpha1.SimulateConfig{
+						Delay:          &metav1.Duration{},
+						FailurePercent: ptr.To(failurePercent),
+					},
+				},
+			}
+		}
+
+		It("should provision with fake addresses", func() {
+			const resourceName = "test-simulate-resource"
+			typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+			resource := newSimulated(resourceName, machine, 0)
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, resource))).To(BeTrue())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Initialization.Provisioned).To(HaveValue(BeTrue()))
+			Expect(resource.Spec.ProviderID).To(Equal("saf://default/" + resourceName))
+			Expect(resource.Status.Addresses).To(ContainElements(
+				capv1beta2.MachineAddress{Type: capv1beta2.MachineHostName, Address: resourceName},
+				HaveField("Type", capv1beta2.MachineInternalIP),
+			))
+		})
+
+		It("should simulate node in workload cluster", func() {
+			const resourceName = "test-simulate-node-resource"
+			typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+			clusterKey := types.NamespacedName{Name: "test-cluster", Namespace: "default"}
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				ClusterCache: clustercache.NewFakeClusterCache(k8sClient, clusterKey),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			machine := newMachine(resourceName, clusterKey.Name)
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+			resource := newSimulated(resourceName, machine, 0)
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, resource))).To(BeTrue())
+			})
+
+			for range 4 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.NodeName).To(Equal(resourceName))
+			node := &corev1.Node{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName}, node)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, node)).To(Succeed())
+			})
+			Expect(node.Spec.ProviderID).To(Equal(resource.Spec.ProviderID))
+			Expect(node.Spec.Taints).To(ContainElement(HaveField("Key", simulate.NodeTaint)))
+			Expect(node.Status.Conditions).To(ContainElement(SatisfyAll(
+				HaveField("Type", corev1.NodeReady),
+				HaveField("Status", corev1.ConditionTrue),
+			)))
+
+			By("making the node unhealthy")
+			node.Status.Conditions[0].Status = corev1.ConditionFalse
+			node.Status.Conditions[0].LastHeartbeatTime = metav1.NewTime(time.Now().Add(-time.Hour))
+			Expect(k8sClient.Status().Update(ctx, node)).To(Succeed())
+			res, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName}, node)).To(Succeed())
+			Expect(node.Status.Conditions).To(ConsistOf(SatisfyAll(
+				HaveField("Type", corev1.NodeReady),
+				HaveField("Status", corev1.ConditionFalse),
+				HaveField("LastHeartbeatTime.Time", BeTemporally("~", time.Now(), time.Minute)),
+			)))
+		})
+
+		It("should fail provisioning by failure percent", func() {
+			const resourceName = "test-simulate-failure-resource"
+			typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+			resource := newSimulated(resourceName, machine, 100)
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Finalizers = nil
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			})
+
+			var err error
+			for range 3 {
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			}
+			Expect(err).To(MatchError(ContainSubstring("Simulated failure of attempt 1")))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ProvisionAttempts).To(ConsistOf(
+				HaveField("Result", v1alpha1.ProvisionAttemptFailed)))
+			Expect(conditions.GetReason(resource, v1alpha1.SAFMachineProvisionedCondition)).
+				To(Equal(v1alpha1.SAFMachineProvisioningFailedReason))
+		})
+	})
+
+	Context("When machine claims a host", func() {
+		ctx := context.Background()
+
+		newHostMachine := func(name string, machine *capv1beta2.Machine) *v1alpha1.SAFMachine {
+			return &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            name,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					HostSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "test-claim"}},
+				},
+			}
+		}
+
+		It("should claim available host and release it after deprovisioning", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{ConnectionConfigEnvPrefix: "SAF_"}),
+			}
+			reconcileAll := func(keys ...types.NamespacedName) {
+				for _, key := range keys {
+					_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+					Expect(err).NotTo(HaveOccurred())
+				}
+			}
+
+			By("creating a host")
+			host := &v1alpha1.SAFHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test-claim-host",
+					Labels: map[string]string{"pool": "test-claim"},
+				},
+				Spec: v1alpha1.SAFHostSpec{
+					ConnectionConfig: map[string]string{"rack": "r1"},
+					SSH: &v1alpha1.SSHProvisioner{
+						Host: "10.0.0.5",
+						KeySecretRef: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "ssh"},
+							Key:                  "ssh-privatekey",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, host)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, host)).To(Succeed())
+			})
+
+			By("creating two machines for a single host")
+			first := types.NamespacedName{Name: "test-claim-first", Namespace: "default"}
+			second := types.NamespacedName{Name: "test-claim-second", Namespace: "default"}
+			for _, key := range []types.NamespacedName{first, second} {
+				machine := newMachine(key.Name, "test-cluster")
+				if key == second {
+					// claims the host, but is never provisioned, as bootstrap data is not generated
+					machine.Spec.Bootstrap = capv1beta2.Bootstrap{ConfigRef: capv1beta2.ContractVersionedObjectReference{
+						APIGroup: "bootstrap.cluster.x-k8s.io",
+						Kind:     "KubeadmConfig",
+						Name:     key.Name,
+					}}
+				}
+				Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+				DeferCleanup(func() {
+					Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+				})
+				Expect(k8sClient.Create(ctx, newHostMachine(key.Name, machine))).To(Succeed())
+			}
+			DeferCleanup(func() {
+				By("deleting the second machine, that claimed the host, but is not provisioned")
+				resource := &v1alpha1.SAFMachine{}
+				Expect(k8sClient.Get(ctx, second, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+				reconcileAll(second)
+				Expect(errors.IsNotFound(k8sClient.Get(ctx, second, resource))).To(BeTrue())
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host)).To(Succeed())
+				Expect(host.Spec.ConsumerRef).To(BeNil())
+			})
+
+			for range 3 {
+				reconcileAll(first, second)
+			}
+
+			By("checking the first machine claimed the host")
+			resource := &v1alpha1.SAFMachine{}
+			Expect(k8sClient.Get(ctx, first, resource)).To(Succeed())
+			Expect(resource.Status.HostRef).To(HaveValue(HaveField("Name", host.Name)))
+			Expect(conditions.IsTrue(resource, v1alpha1.SAFMachineHostClaimedCondition)).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host)).To(Succeed())
+			Expect(host.Spec.ConsumerRef).To(HaveValue(HaveField("Name", first.Name)))
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostProvisioning))
+
+			provisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: first.Name + "-provision", Namespace: "default",
+			}, provisionJob)).To(Succeed())
+			container := provisionJob.Spec.Template.Spec.Containers[0]
+			Expect(container.Name).To(Equal("ssh"))
+			Expect(container.Env).To(ContainElements(
+				corev1.EnvVar{Name: "SAF_SSH_HOST", Value: "10.0.0.5"},
+				corev1.EnvVar{Name: "SAF_RACK", Value: "r1"},
+				corev1.EnvVar{Name: "SAF_HOST_NAME", Value: host.Name},
+			))
+
+			By("checking the second machine waits for a host")
+			Expect(k8sClient.Get(ctx, second, resource)).To(Succeed())
+			Expect(resource.Status.HostRef).To(BeNil())
+			Expect(conditions.GetReason(resource, v1alpha1.SAFMachineHostClaimedCondition)).
+				To(Equal(v1alpha1.SAFMachineWaitingForHostReason))
+
+			By("provisioning and deleting the first machine")
+			completeJob(ctx, provisionJob)
+			reconcileAll(first)
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host)).To(Succeed())
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostProvisioned))
+			Expect(k8sClient.Get(ctx, first, resource)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			reconcileAll(first)
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host)).To(Succeed())
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostDeprovisioning))
+
+			deprovisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: first.Name + "-deprovision", Namespace: "default",
+			}, deprovisionJob)).To(Succeed())
+			completeJob(ctx, deprovisionJob)
+			reconcileAll(first)
+
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, first, resource))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(host), host)).To(Succeed())
+			Expect(host.Spec.ConsumerRef).To(BeNil())
+			Expect(host.Status.State).To(Equal(v1alpha1.SAFHostAvailable))
+
+			By("checking the second machine claimed the released host")
+			reconcileAll(second)
+			Expect(k8sClient.Get(ctx, second, resource)).To(Succeed())
+			Expect(resource.Status.HostRef).To(HaveValue(HaveField("Name", host.Name)))
+		})
+
+		It("should report claimed host, that is not found", func() {
+			const resourceName = "test-claim-missing"
+			typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+			resource := newHostMachine(resourceName, machine)
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			DeferCleanup(func() {
+				By("deleting the machine, whose host is gone")
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).To(MatchError(ContainSubstring("get claimed host")))
+				Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, resource))).To(BeTrue())
+			})
+
+			By("recording a claimed host, that is deleted since")
+			resource.Status.HostRef = &corev1.LocalObjectReference{Name: resourceName + "-host"}
+			conditions.Set(resource, metav1.Condition{
+				Type:   v1alpha1.SAFMachineHostClaimedCondition,
+				Status: metav1.ConditionTrue,
+				Reason: v1alpha1.SAFMachineHostClaimedReason,
+			})
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+
+			var err error
+			for range 3 {
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			}
+			Expect(err).To(MatchError(ContainSubstring("get claimed host")))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(conditions.IsFalse(resource, v1alpha1.SAFMachineHostClaimedCondition)).To(BeTrue())
+			Expect(conditions.GetReason(resource, v1alpha1.SAFMachineHostClaimedCondition)).
+				To(Equal(v1alpha1.SAFMachineHostNotFoundReason))
+		})
+
+		It("should remove finalizer of the machine, deleted before it claimed a host", func() {
+			const resourceName = "test-claim-unclaimed"
+			typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+			By("creating a machine, that is provisioned over ssh of a host, that doesn't exist")
+			resource := newHostMachine(resourceName, machine)
+			resource.Spec.HostSelector.MatchLabels["pool"] = resourceName
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(conditions.GetReason(resource, v1alpha1.SAFMachineHostClaimedCondition)).
+				To(Equal(v1alpha1.SAFMachineWaitingForHostReason))
+
+			By("deleting the machine")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, typeNamespacedName, resource))).To(BeTrue())
+
+			jobs := &batchv1.JobList{}
+			Expect(k8sClient.List(ctx, jobs, client.InNamespace("default"),
+				client.MatchingLabels{v1alpha1.SAFMachineNameLabel: resourceName})).To(Succeed())
+			Expect(jobs.Items).To(BeEmpty())
+		})
+	})
+
+	Context("When machine is placed into failure domain", func() {
+		const resourceName = "test-failure-domain"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should claim host of the failure domain and run jobs on its nodes", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{ConnectionConfigEnvPrefix: "SAF_"}),
+			}
+
+			By("creating cluster with failure domains")
+			safcl := &v1alpha1.SAFCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: v1alpha1.SAFClusterSpec{
+					FailureDomains: []v1alpha1.SAFFailureDomain{
+						{Name: "rack-a", ControlPlane: ptr.To(true), JobNodeSelector: map[string]string{"rack": "a"}},
+						{Name: "rack-b", ControlPlane: ptr.To(true), JobNodeSelector: map[string]string{"rack": "b"}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, safcl)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safcl)).To(Succeed())
+			})
+			cluster := &capv1beta2.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: capv1beta2.ClusterSpec{
+					InfrastructureRef: capv1beta2.ContractVersionedObjectReference{
+						APIGroup: v1alpha1.GroupVersion.Group,
+						Kind:     v1alpha1.SAFClusterKind,
+						Name:     safcl.Name,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, cluster)).To(Succeed())
+			})
+
+			By("creating a host in every failure domain")
+			for _, rack := range []string{"a", "b"} {
+				host := &v1alpha1.SAFHost{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: resourceName + "-host-" + rack,
+						Labels: map[string]string{
+							"pool":                      resourceName,
+							v1alpha1.FailureDomainLabel: "rack-" + rack,
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, host)).To(Succeed())
+				DeferCleanup(func() {
+					Expect(k8sClient.Delete(ctx, host)).To(Succeed())
+				})
+			}
+
+			By("creating machine in the second failure domain")
+			machine := newMachine(resourceName, cluster.Name)
+			machine.Spec.FailureDomain = "rack-b"
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+			safm := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					HostSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"pool": resourceName}},
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, safm)).To(Succeed())
+				safm.Finalizers = nil
+				Expect(k8sClient.Update(ctx, safm)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, safm)).To(Succeed())
+			Expect(safm.Status.HostRef).To(HaveValue(HaveField("Name", resourceName+"-host-b")))
+
+			provisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: resourceName + "-provision", Namespace: "default",
+			}, provisionJob)).To(Succeed())
+			Expect(provisionJob.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"rack": "b"}))
+			Expect(provisionJob.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "SAF_FAILURE_DOMAIN", Value: "rack-b"}))
+		})
+	})
+
+	Context("When machine has inspection job", func() {
+		const resourceName = "test-inspection"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		It("should block provisioning until host meets requirements", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+			reconcileMachine := func() {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+			safm := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+					InspectionJob:  ptr.To(jobTemplate()),
+					HostRequirements: &v1alpha1.HostRequirements{
+						Architecture: "amd64",
+						Memory:       ptr.To(resource.MustParse("8Gi")),
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, safm)).To(Succeed())
+				safm.Finalizers = nil
+				Expect(k8sClient.Update(ctx, safm)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			for range 3 {
+				reconcileMachine()
+			}
+
+			By("checking inspection job is created before provision job")
+			inspectionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name: resourceName + "-inspect", Namespace: "default",
+			}, inspectionJob)).To(Succeed())
+			for _, volume := range inspectionJob.Spec.Template.Spec.Volumes {
+				Expect(volume.Name).NotTo(Equal(v1alpha1.BootstrapVolumeName))
+			}
+			provisionJobKey := types.NamespacedName{Name: resourceName + "-provision", Namespace: "default"}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, provisionJobKey, &batchv1.Job{}))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, safm)).To(Succeed())
+			Expect(conditions.GetReason(safm, v1alpha1.SAFMachineHostInspectedCondition)).
+				To(Equal(v1alpha1.SAFMachineInspectingReason))
+
+			By("completing inspection job with facts of too small host")
+			inspectionPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName + "-inspect-abcde",
+					Namespace: "default",
+					Labels:    map[string]string{batchv1.JobNameLabel: inspectionJob.Name},
+				},
+				Spec: *inspectionJob.Spec.Template.Spec.DeepCopy(),
+			}
+			Expect(k8sClient.Create(ctx, inspectionPod)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, inspectionPod)).To(Succeed())
+			})
+			inspectionPod.Status.Phase = corev1.PodSucceeded
+			inspectionPod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:  "main",
+				Image: "main",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: `{"architecture":"amd64","os":"Ubuntu 24.04","cpus":8,"memory":"4Gi",` +
+						`"disks":[{"name":"sda","size":"500Gi"}],"nics":[{"name":"eth0","macAddress":"52:54:00:12:34:56"}]}`,
+				}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, inspectionPod)).To(Succeed())
+			completeJob(ctx, inspectionJob)
+			reconcileMachine()
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, safm)).To(Succeed())
+			Expect(safm.Status.HostFacts).NotTo(BeNil())
+			Expect(safm.Status.HostFacts.CPUs).To(Equal(int32(8)))
+			Expect(safm.Status.HostFacts.Memory.String()).To(Equal("4Gi"))
+			Expect(safm.Status.HostFacts.NICs).To(ConsistOf(
+				v1alpha1.HostNIC{Name: "eth0", MACAddress: "52:54:00:12:34:56"}))
+			Expect(conditions.GetReason(safm, v1alpha1.SAFMachineHostInspectedCondition)).
+				To(Equal(v1alpha1.SAFMachineRequirementsNotMetReason))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, provisionJobKey, &batchv1.Job{}))).To(BeTrue())
+
+			By("lowering requirements")
+			safm.Spec.HostRequirements.Memory = ptr.To(resource.MustParse("4Gi"))
+			Expect(k8sClient.Update(ctx, safm)).To(Succeed())
+			reconcileMachine()
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, safm)).To(Succeed())
+			Expect(conditions.IsTrue(safm, v1alpha1.SAFMachineHostInspectedCondition)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, provisionJobKey, &batchv1.Job{})).To(Succeed())
+		})
+	})
+
+	Context("When deleting a resource", func() {
+		const resourceName = "test-deleting-resource"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+		deprovisionJobKey := types.NamespacedName{
+			Name:      resourceName + "-deprovision",
+			Namespace: "default",
+		}
+
+		It("should remove finalizer only after deprovision job succeeded", func() {
+			controllerReconciler := &safmachine.Reconciler{
+				Client:       k8sClient,
+				Scheme:       k8sClient.Scheme(),
+				Provisioners: provisioners(&job.Provisioner{}),
+			}
+
+			By("creating owner machine")
+			machine := newMachine(resourceName, "test-cluster")
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, machine)).To(Succeed())
+			})
+
+			By("creating the custom resource for the Kind SAFMachine")
+			resource := &v1alpha1.SAFMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            resourceName,
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{machineOwnerRef(machine)},
+				},
+				Spec: v1alpha1.SAFMachineSpec{
+					ProvisionJob:   jobTemplate(),
+					DeprovisionJob: jobTemplate(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("completing the provision job")
+			provisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-provision", Namespace: "default"},
+				provisionJob)).To(Succeed())
+			completeJob(ctx, provisionJob)
+
+			By("deleting the resource")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deprovisionJob := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, deprovisionJobKey, deprovisionJob)).To(Succeed())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Finalizers).To(ContainElement(v1alpha1.SAFMachineFinalizer))
+			Expect(conditions.GetReason(resource, v1alpha1.SAFMachineDeprovisionedCondition)).
+				To(Equal(v1alpha1.SAFMachineDeprovisioningReason))
+			Expect(conditions.IsFalse(resource, v1alpha1.SAFMachineReadyCondition)).To(BeTrue())
+
+			By("completing the deprovision job")
+			completeJob(ctx, deprovisionJob)
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
 })
+
+func jobTemplate() v1alpha1.JobTemplate {
+	return v1alpha1.JobTemplate{
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "main",
+							Image: "main",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// provisioners registers the job provisioner, configured by the test, and the other backends with their defaults.
+func provisioners(jobProvisioner *job.Provisioner) map[string]provisioner.Provisioner {
+	jobProvisioner.Client = k8sClient
+	jobProvisioner.Scheme = k8sClient.Scheme()
+	return map[string]provisioner.Provisioner{
+		v1alpha1.JobProvisioner:      jobProvisioner,
+		v1alpha1.NoopProvisioner:     &noop.Provisioner{},
+		v1alpha1.SimulateProvisioner: &simulate.Provisioner{},
+	}
+}
+
+// completeJob marks job as succeeded, envtest has no job controller to do it.
+func completeJob(ctx context.Context, job *batchv1.Job) {
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.CompletionTime = &now
+	job.Status.Succeeded = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue, LastTransitionTime: now},
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: now},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
+
+// failJob marks job as failed, envtest has no job controller to do it.
+func failJob(ctx context.Context, job *batchv1.Job) {
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.Failed = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobFailureTarget, Status: corev1.ConditionTrue, LastTransitionTime: now, Reason: "BackoffLimitExceeded"},
+		{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, LastTransitionTime: now, Reason: "BackoffLimitExceeded",
+			Message: "Job has reached the specified backoff limit"},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
+
+func secretKeyRef(name, key string) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		Key:                  key,
+	}}
+}
+
+func newMachine(name, clusterName string) *capv1beta2.Machine {
+	return &capv1beta2.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: capv1beta2.MachineSpec{
+			ClusterName: clusterName,
+			Bootstrap: capv1beta2.Bootstrap{
+				DataSecretName: ptr.To(name + "-bootstrap"),
+			},
+			InfrastructureRef: capv1beta2.ContractVersionedObjectReference{
+				APIGroup: v1alpha1.GroupVersion.Group,
+				Kind:     v1alpha1.SAFMachineKind,
+				Name:     name,
+			},
+		},
+	}
+}
+
+func machineOwnerRef(machine *capv1beta2.Machine) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: capv1beta2.GroupVersion.String(),
+		Kind:       "Machine",
+		Name:       machine.Name,
+		UID:        machine.UID,
+	}
+}
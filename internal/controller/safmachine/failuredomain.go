@@ -0,0 +1,70 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachine
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// findFailureDomain looks the failure domain of the Machine up in the SAFCluster of its Cluster.
+// Deleting SAFMachine is deprovisioned without the failure domain, if the SAFCluster is already gone.
+func (r *Reconciler) findFailureDomain(ctx context.Context, s *scope) error {
+	if s.machine == nil || s.machine.Spec.FailureDomain == "" || s.cluster == nil {
+		return nil
+	}
+	ref := s.cluster.Spec.InfrastructureRef
+	if ref.APIGroup != v1alpha1.GroupVersion.Group || ref.Kind != v1alpha1.SAFClusterKind {
+		return nil
+	}
+	deleting := s.safMachine.GetDeletionTimestamp() != nil
+
+	safcl := &v1alpha1.SAFCluster{}
+	key := types.NamespacedName{Namespace: s.cluster.Namespace, Name: ref.Name}
+	if err := r.Get(ctx, key, safcl); apierrors.IsNotFound(err) && deleting {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("get saf cluster %s: %w", key.Name, err)
+	}
+
+	name := s.machine.Spec.FailureDomain
+	for i := range safcl.Spec.FailureDomains {
+		if safcl.Spec.FailureDomains[i].Name == name {
+			s.failureDomain = &safcl.Spec.FailureDomains[i]
+			return nil
+		}
+	}
+	if deleting {
+		return nil
+	}
+	return fmt.Errorf("failure domain %q is not defined in SAFCluster %s", name, safcl.Name)
+}
+
+// failureDomainHostSelector returns the selector of SAFHosts in the failure domain.
+func failureDomainHostSelector(fd *v1alpha1.SAFFailureDomain) (labels.Selector, error) {
+	if fd.HostSelector == nil {
+		return labels.SelectorFromSet(labels.Set{v1alpha1.FailureDomainLabel: fd.Name}), nil
+	}
+	return metav1.LabelSelectorAsSelector(fd.HostSelector)
+}
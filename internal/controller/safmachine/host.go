@@ -0,0 +1,236 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// claimHost claims an available SAFHost, matching SAFMachine's hostSelector and its failure domain.
+// Host is claimed by setting its consumerRef, so update conflicts prevent two SAFMachines from claiming the same host.
+func (r *Reconciler) claimHost(ctx context.Context, s *scope) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "claimHost")
+	safm := s.safMachine
+
+	if safm.Status.HostRef != nil {
+		host := &v1alpha1.SAFHost{}
+		key := types.NamespacedName{Name: safm.Status.HostRef.Name}
+		if err := r.Get(ctx, key, host); err != nil {
+			return ctrl.Result{}, fmt.Errorf("get claimed host %s: %w", key.Name, err)
+		}
+		if !isHostConsumer(host, safm) && !isMovedHostConsumer(host, safm) {
+			return ctrl.Result{}, fmt.Errorf("host %s is not claimed by the SAFMachine", host.Name)
+		}
+		if !isHostConsumer(host, safm) {
+			// SAFMachine is recreated with new uid by clusterctl move, host is still claimed by it
+			l.Info("adopt host claimed before move", "host_name", host.Name)
+			host.Spec.ConsumerRef.UID = safm.UID
+			if err := r.Update(ctx, host); err != nil {
+				return ctrl.Result{}, fmt.Errorf("adopt host %s: %w", host.Name, err)
+			}
+		}
+		s.host = host
+		return ctrl.Result{}, nil
+	}
+
+	if safm.Spec.HostSelector == nil || safm.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(safm.Spec.HostSelector)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("parse host selector: %w", err)
+	}
+	if s.failureDomain != nil {
+		fdSelector, err := failureDomainHostSelector(s.failureDomain)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("parse host selector of failure domain %s: %w", s.failureDomain.Name, err)
+		}
+		requirements, _ := fdSelector.Requirements()
+		selector = selector.Add(requirements...)
+	}
+	hosts := &v1alpha1.SAFHostList{}
+	if err := r.List(ctx, hosts, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("list hosts: %w", err)
+	}
+	slices.SortFunc(hosts.Items, func(a, b v1alpha1.SAFHost) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	for i := range hosts.Items {
+		host := &hosts.Items[i]
+		// host may be already claimed by this SAFMachine, if its status was not saved
+		if host.GetDeletionTimestamp() != nil || host.Spec.ConsumerRef != nil && !isHostConsumer(host, safm) {
+			continue
+		}
+		if host.Spec.ConsumerRef == nil && !isHostAvailable(host) {
+			// being cleaned after previous consumer
+			continue
+		}
+
+		host.Spec.ConsumerRef = &corev1.ObjectReference{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       v1alpha1.SAFMachineKind,
+			Namespace:  safm.Namespace,
+			Name:       safm.Name,
+			UID:        safm.UID,
+		}
+		if err := r.Update(ctx, host); apierrors.IsConflict(err) {
+			// claimed by someone else in the meantime
+			continue
+		} else if err != nil {
+			return ctrl.Result{}, fmt.Errorf("claim host %s: %w", host.Name, err)
+		}
+		host.Status.State = v1alpha1.SAFHostProvisioning
+		if err := r.Status().Update(ctx, host); err != nil {
+			return ctrl.Result{}, fmt.Errorf("update status of claimed host %s: %w", host.Name, err)
+		}
+
+		l.Info("claimed host", "host_name", host.Name)
+		safm.Status.HostRef = &corev1.LocalObjectReference{Name: host.Name}
+		s.host = host
+		return ctrl.Result{}, nil
+	}
+
+	// will requeue on host update
+	l.Info("no available host matches host selector")
+	return ctrl.Result{}, nil
+}
+
+// syncHostState reports the lifecycle state of the SAFMachine on its claimed host.
+func (r *Reconciler) syncHostState(ctx context.Context, s *scope) (ctrl.Result, error) {
+	if s.host == nil {
+		return ctrl.Result{}, nil
+	}
+
+	state := v1alpha1.SAFHostProvisioning
+	switch {
+	case s.safMachine.GetDeletionTimestamp() != nil:
+		state = v1alpha1.SAFHostDeprovisioning
+	case ptr.Deref(s.safMachine.Status.Initialization.Provisioned, false):
+		state = v1alpha1.SAFHostProvisioned
+	}
+	if s.host.Status.State == state {
+		return ctrl.Result{}, nil
+	}
+
+	logf.FromContext(ctx).Info("update host state", "host_name", s.host.Name, "state", state)
+	s.host.Status.State = state
+	if err := r.Status().Update(ctx, s.host); err != nil {
+		return ctrl.Result{}, fmt.Errorf("update state of host %s: %w", s.host.Name, err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// releaseHost gives the claimed host up, after it is deprovisioned. The host becomes available for other SAFMachines
+// right away, or after its cleaning job succeeds.
+func (r *Reconciler) releaseHost(ctx context.Context, s *scope) error {
+	l := logf.FromContext(ctx)
+
+	if s.host == nil {
+		return nil
+	}
+
+	s.host.Spec.ConsumerRef = nil
+	if err := r.Update(ctx, s.host); err != nil {
+		return fmt.Errorf("release host %s: %w", s.host.Name, err)
+	}
+	s.host.Status.State = v1alpha1.SAFHostAvailable
+	if s.host.Spec.CleaningJob != nil {
+		s.host.Status.State = v1alpha1.SAFHostCleaning
+		// Secrets of the host exist in the namespace of its consumer, as its jobs use them
+		s.host.Status.CleaningJobNamespace = s.safMachine.Namespace
+	}
+	if err := r.Status().Update(ctx, s.host); err != nil {
+		return fmt.Errorf("update status of released host %s: %w", s.host.Name, err)
+	}
+
+	l.Info("released host", "host_name", s.host.Name)
+	s.safMachine.Status.HostRef = nil
+	s.host = nil
+	return nil
+}
+
+// isHostAvailable reports, if the host may be claimed. New hosts have no state, until SAFHost controller sets it.
+func isHostAvailable(host *v1alpha1.SAFHost) bool {
+	return host.Status.State == "" || host.Status.State == v1alpha1.SAFHostAvailable
+}
+
+func isHostConsumer(host *v1alpha1.SAFHost, safm *v1alpha1.SAFMachine) bool {
+	ref := host.Spec.ConsumerRef
+	return ref != nil && ref.Kind == v1alpha1.SAFMachineKind && ref.Name == safm.Name && ref.UID == safm.UID
+}
+
+// isMovedHostConsumer reports, if the host is claimed by the SAFMachine with the same name, but another uid,
+// and the status of the SAFMachine, restored after clusterctl move, refers to the host.
+func isMovedHostConsumer(host *v1alpha1.SAFHost, safm *v1alpha1.SAFMachine) bool {
+	ref := host.Spec.ConsumerRef
+	return ref != nil && ref.Kind == v1alpha1.SAFMachineKind && ref.Name == safm.Name &&
+		ref.Namespace == safm.Namespace && safm.Status.HostRef != nil && safm.Status.HostRef.Name == host.Name
+}
+
+// safHostToSAFMachines maps the host to its consumer, or to SAFMachines of any namespace waiting for a host,
+// when it is available.
+func (r *Reconciler) safHostToSAFMachines(ctx context.Context, o client.Object) []reconcile.Request {
+	l := logf.FromContext(ctx)
+
+	host, ok := o.(*v1alpha1.SAFHost)
+	if !ok {
+		return nil
+	}
+	if ref := host.Spec.ConsumerRef; ref != nil {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}}}
+	}
+	if !isHostAvailable(host) {
+		return nil
+	}
+
+	safms := &v1alpha1.SAFMachineList{}
+	if err := r.List(ctx, safms); err != nil {
+		l.Error(err, "list SAFMachines waiting for host", "host_name", host.Name)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, safm := range safms.Items {
+		if safm.Spec.HostSelector == nil || safm.Status.HostRef != nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(safm.Spec.HostSelector)
+		if err != nil || !selector.Matches(labels.Set(host.Labels)) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&safm)})
+	}
+	return requests
+}
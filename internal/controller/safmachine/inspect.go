@@ -0,0 +1,108 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
+)
+
+// inspect runs the inspection job once, before provisioning, and records reported host facts.
+// Failed inspection is not retried, until the failed job is deleted.
+func (r *Reconciler) inspect(ctx context.Context, s *scope) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "inspect")
+	safm := s.safMachine
+
+	if safm.Spec.InspectionJob == nil || safm.Status.HostFacts != nil || safm.GetDeletionTimestamp() != nil ||
+		ptr.Deref(safm.Status.Initialization.Provisioned, false) {
+		return ctrl.Result{}, nil
+	}
+	inspector, ok := s.provisioner.(provisioner.Inspector)
+	if !ok {
+		return ctrl.Result{}, fmt.Errorf("provisioner %q doesn't support inspection", safm.Spec.Provisioner)
+	}
+
+	req := s.request(1)
+	status, err := s.provisioner.Status(ctx, req, provisioner.OperationInspect)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("get status of inspection: %w", err)
+	}
+	if status.State == provisioner.StateNotStarted {
+		if safm.Spec.HostSelector != nil && s.host == nil {
+			// will requeue on host update
+			l.Info("safMachine's host is not claimed")
+			return ctrl.Result{}, nil
+		}
+		l.Info("start inspection")
+		if status, err = inspector.Inspect(ctx, req); err != nil {
+			return ctrl.Result{}, fmt.Errorf("start inspection: %w", err)
+		}
+	}
+	s.inspectionStatus = &status
+
+	switch status.State {
+	case provisioner.StateSucceeded:
+		if status.Facts == nil {
+			return ctrl.Result{}, fmt.Errorf("inspection job %s reported no host facts", status.Name)
+		}
+		l.Info("host inspected", "inspection_job_name", status.Name)
+		safm.Status.HostFacts = status.Facts
+	case provisioner.StateFailed:
+		// will requeue, when the failed job is deleted
+		l.Info("inspection failed", "inspection_job_name", status.Name, "message", status.Message)
+	}
+	return ctrl.Result{RequeueAfter: status.RequeueAfter}, nil
+}
+
+// unmetRequirements describes requirements, that the host doesn't meet.
+func unmetRequirements(requirements *v1alpha1.HostRequirements, facts *v1alpha1.HostFacts) []string {
+	if requirements == nil || facts == nil {
+		return nil
+	}
+
+	var unmet []string
+	if requirements.Architecture != "" && requirements.Architecture != facts.Architecture {
+		unmet = append(unmet, fmt.Sprintf("architecture is %q, required %q", facts.Architecture, requirements.Architecture))
+	}
+	if requirements.CPUs != nil && facts.CPUs < *requirements.CPUs {
+		unmet = append(unmet, fmt.Sprintf("host has %d CPUs, required %d", facts.CPUs, *requirements.CPUs))
+	}
+	if memory := ptr.Deref(facts.Memory, resource.Quantity{}); requirements.Memory != nil &&
+		memory.Cmp(*requirements.Memory) < 0 {
+		unmet = append(unmet, fmt.Sprintf("host has %s of memory, required %s",
+			memory.String(), requirements.Memory.String()))
+	}
+	if requirements.DiskSize != nil {
+		largest := slices.MaxFunc(append([]v1alpha1.HostDisk{{}}, facts.Disks...), func(a, b v1alpha1.HostDisk) int {
+			return a.Size.Cmp(b.Size)
+		})
+		if largest.Size.Cmp(*requirements.DiskSize) < 0 {
+			unmet = append(unmet, fmt.Sprintf("largest disk has %s, required %s",
+				largest.Size.String(), requirements.DiskSize.String()))
+		}
+	}
+	return unmet
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachine
+
+import (
+	"context"
+	"fmt"
+
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	utilexp "sigs.k8s.io/cluster-api/exp/util"
+	"sigs.k8s.io/cluster-api/util/labels"
+	"sigs.k8s.io/cluster-api/util/labels/format"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// withMachinePoolBootstrap gives the Machine of a SAFMachinePool instance the bootstrap data of its MachinePool,
+// as MachinePool creates Machines without bootstrap data. Other Machines are returned as is.
+func (r *Reconciler) withMachinePoolBootstrap(ctx context.Context, ma *capv1beta2.Machine) (*capv1beta2.Machine, error) {
+	if ma == nil || !labels.IsMachinePoolOwned(ma) || ma.Spec.Bootstrap.DataSecretName != nil && *ma.Spec.Bootstrap.DataSecretName != "" {
+		return ma, nil
+	}
+
+	mp, err := utilexp.GetMachinePoolByLabels(ctx, r.Client, ma.Namespace, ma.Labels)
+	if err != nil {
+		return nil, fmt.Errorf("get machine pool of machine %s: %w", ma.Name, err)
+	}
+
+	ma = ma.DeepCopy()
+	ma.Spec.Bootstrap.DataSecretName = nil
+	if mp != nil {
+		ma.Spec.Bootstrap.DataSecretName = mp.Spec.Template.Spec.Bootstrap.DataSecretName
+	}
+	return ma, nil
+}
+
+// machinePoolToSAFMachines maps the MachinePool to its SAFMachinePool instances, that wait for its bootstrap data.
+func (r *Reconciler) machinePoolToSAFMachines(ctx context.Context, o client.Object) []reconcile.Request {
+	l := logf.FromContext(ctx)
+
+	mp, ok := o.(*capv1beta2.MachinePool)
+	if !ok || mp.Spec.Template.Spec.InfrastructureRef.Kind != v1alpha1.SAFMachinePoolKind {
+		return nil
+	}
+
+	list := &v1alpha1.SAFMachineList{}
+	if err := r.List(ctx, list, client.InNamespace(mp.Namespace), client.MatchingLabels{
+		capv1beta2.ClusterNameLabel:     mp.Spec.ClusterName,
+		capv1beta2.MachinePoolNameLabel: format.MustFormatValue(mp.Name),
+	}); err != nil {
+		l.Error(err, "list saf machines of machine pool", "machine_pool_name", mp.Name)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, safm := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&safm)})
+	}
+	return requests
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachine
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/movestate"
+)
+
+// restoreStatus restores status of the SAFMachine, moved from another management cluster, from its annotation.
+// Provisioning state lives on the SAFMachine, so moved machines are not provisioned again, although their jobs are gone.
+func restoreStatus(safm *v1alpha1.SAFMachine) error {
+	if !equality.Semantic.DeepEqual(durableStatus(safm), v1alpha1.SAFMachineStatus{}) {
+		return nil
+	}
+	status := v1alpha1.SAFMachineStatus{}
+	if ok, err := movestate.Restore(safm, &status); err != nil || !ok {
+		return err
+	}
+	status.Conditions = safm.Status.Conditions
+	safm.Status = status
+	return nil
+}
+
+// saveStatus keeps status of the SAFMachine in its annotation, as clusterctl move doesn't move status.
+func saveStatus(safm *v1alpha1.SAFMachine) error {
+	return movestate.Save(safm, durableStatus(safm))
+}
+
+// durableStatus is the status without conditions, that are recalculated on every reconciliation.
+func durableStatus(safm *v1alpha1.SAFMachine) v1alpha1.SAFMachineStatus {
+	status := *safm.Status.DeepCopy()
+	status.Conditions = nil
+	return status
+}
@@ -0,0 +1,446 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package safmachinepool reconciles SAFMachinePools. Instances of the pool are SAFMachines,
+// so every instance is provisioned and deprovisioned by its own jobs, as a stand-alone SAFMachine.
+package safmachinepool
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	utilexp "sigs.k8s.io/cluster-api/exp/util"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/finalizers"
+	"sigs.k8s.io/cluster-api/util/labels/format"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/paused"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// Reconciler reconciles a SAFMachinePool object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+var controllerName = strings.ToLower(v1alpha1.SAFMachinePoolKind)
+
+const (
+	// stateConfirmationInterval is how often the cache is checked for created instances.
+	stateConfirmationInterval = 100 * time.Millisecond
+	// stateConfirmationTimeout is how long created instances are waited for in the cache.
+	stateConfirmationTimeout = 10 * time.Second
+)
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	l := mgr.GetLogger().WithValues("controller", controllerName, "predicate", "true")
+	ctx := logf.IntoContext(context.Background(), l)
+	clusterToSAFMachinePools, err := util.ClusterToTypedObjectsMapper(mgr.GetClient(), &v1alpha1.SAFMachinePoolList{}, mgr.GetScheme())
+	if err != nil {
+		return fmt.Errorf("make cluster to saf machine pools mapper: %w", err)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.SAFMachinePool{}).
+		Watches(
+			&v1alpha1.SAFMachine{},
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &v1alpha1.SAFMachinePool{}),
+			builder.WithPredicates(predicates.ResourceIsChanged(mgr.GetScheme(), l)),
+		).
+		Watches(
+			&capv1beta2.MachinePool{},
+			handler.EnqueueRequestsFromMapFunc(utilexp.MachinePoolToInfrastructureMapFunc(ctx,
+				v1alpha1.GroupVersion.WithKind(v1alpha1.SAFMachinePoolKind))),
+			builder.WithPredicates(predicates.ResourceIsChanged(mgr.GetScheme(), l)),
+		).
+		Watches(
+			&capv1beta2.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(clusterToSAFMachinePools),
+			builder.WithPredicates(predicates.ClusterPausedTransitions(mgr.GetScheme(), l)),
+		).
+		Named(controllerName).
+		Complete(r)
+}
+
+type scope struct {
+	cluster        *capv1beta2.Cluster
+	machinePool    *capv1beta2.MachinePool
+	safMachinePool *v1alpha1.SAFMachinePool
+	// instances are SAFMachines of the pool, that are not deleting.
+	instances []v1alpha1.SAFMachine
+	// deleting is the number of SAFMachines of the pool, that are deleting.
+	deleting int
+}
+
+// desiredReplicas is the number of instances, that the MachinePool wants, it defaults to 1 as in MachinePool.
+func (s *scope) desiredReplicas() int {
+	return int(ptr.Deref(s.machinePool.Spec.Replicas, 1))
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safmachinepools,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safmachinepools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safmachinepools/finalizers,verbs=update
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safmachines,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinepools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	l := logf.FromContext(ctx)
+
+	safmp := &v1alpha1.SAFMachinePool{}
+	if err := r.Get(ctx, req.NamespacedName, safmp); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("get saf machine pool: %w", err))
+	}
+
+	mp, err := utilexp.GetOwnerMachinePool(ctx, r.Client, safmp.ObjectMeta)
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, fmt.Errorf("get owner machine pool: %w", err)
+	}
+	s := &scope{
+		machinePool:    mp,
+		safMachinePool: safmp,
+	}
+	clusterName := safmp.Labels[capv1beta2.ClusterNameLabel]
+	if mp != nil {
+		clusterName = mp.Spec.ClusterName
+	}
+	if clusterName != "" {
+		cl, err := util.GetClusterByName(ctx, r.Client, safmp.Namespace, clusterName)
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, fmt.Errorf("get owner cluster: %w", err)
+		}
+		s.cluster = cl
+	}
+
+	if isPaused, requeue, err := paused.EnsurePausedCondition(ctx, r.Client, s.cluster, safmp); err != nil || isPaused || requeue {
+		return ctrl.Result{}, err
+	}
+
+	if changed, err := finalizers.EnsureFinalizer(ctx, r.Client, safmp, v1alpha1.SAFMachinePoolFinalizer); changed || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pacher, err := patch.NewHelper(safmp, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("make patcher: %w", err)
+	}
+	defer func() {
+		calculateStatus(ctx, s)
+		opts := []patch.Option{
+			patch.WithOwnedConditions{Conditions: []string{
+				v1alpha1.SAFMachinePoolReadyCondition,
+				v1alpha1.SAFMachinePoolReplicasProvisionedCondition,
+				v1alpha1.SAFMachinePoolPausedCondition,
+			}},
+		}
+		if reterr == nil {
+			opts = append(opts, patch.WithStatusObservedGeneration{})
+		}
+		if err := pacher.Patch(ctx, safmp, opts...); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	safmp.Status.InfrastructureMachineKind = v1alpha1.SAFMachineKind
+	if err := r.listInstances(ctx, s); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if safmp.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, r.reconcileDelete(ctx, s)
+	}
+
+	if mp == nil {
+		// will requeue, when MachinePool sets owner reference
+		l.Info("waiting for MachinePool to set owner reference")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.scale(ctx, s); err != nil {
+		return ctrl.Result{}, err
+	}
+	syncReplicas(s)
+
+	return ctrl.Result{}, nil
+}
+
+// listInstances finds SAFMachines of the pool.
+func (r *Reconciler) listInstances(ctx context.Context, s *scope) error {
+	safmp := s.safMachinePool
+	list := &v1alpha1.SAFMachineList{}
+	if err := r.List(ctx, list, client.InNamespace(safmp.Namespace),
+		client.MatchingLabels{v1alpha1.SAFMachinePoolNameLabel: format.MustFormatValue(safmp.Name)}); err != nil {
+		return fmt.Errorf("list saf machines: %w", err)
+	}
+
+	for _, safm := range list.Items {
+		if !isInstanceOf(&safm, safmp) {
+			continue
+		}
+		if safm.GetDeletionTimestamp() != nil {
+			s.deleting++
+			continue
+		}
+		s.instances = append(s.instances, safm)
+	}
+	return nil
+}
+
+// isInstanceOf reports, if the SAFMachine is owned by the pool.
+func isInstanceOf(safm *v1alpha1.SAFMachine, safmp *v1alpha1.SAFMachinePool) bool {
+	for _, ref := range safm.OwnerReferences {
+		if ref.Kind == v1alpha1.SAFMachinePoolKind && ref.Name == safmp.Name &&
+			strings.HasPrefix(ref.APIVersion, v1alpha1.GroupVersion.Group+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// scale creates missing instances and deletes excess ones. Instances, that are not provisioned yet,
+// are deleted first, then the newest ones.
+func (r *Reconciler) scale(ctx context.Context, s *scope) error {
+	l := logf.FromContext(ctx)
+	desired := s.desiredReplicas()
+
+	var created []client.ObjectKey
+	for len(s.instances) < desired {
+		safm, err := r.createInstance(ctx, s)
+		if err != nil {
+			return kerrors.NewAggregate([]error{err, r.waitForInstancesCreation(ctx, created)})
+		}
+		l.Info("instance created", "safmachine_name", safm.Name, "replicas", desired)
+		s.instances = append(s.instances, *safm)
+		created = append(created, client.ObjectKeyFromObject(safm))
+	}
+	if err := r.waitForInstancesCreation(ctx, created); err != nil {
+		return err
+	}
+
+	if excess := len(s.instances) - desired; excess > 0 {
+		slices.SortStableFunc(s.instances, deletePriority)
+		for i := range s.instances[:excess] {
+			l.Info("delete excess instance", "safmachine_name", s.instances[i].Name, "replicas", desired)
+			if err := r.deleteInstance(ctx, &s.instances[i]); err != nil {
+				return err
+			}
+			s.deleting++
+		}
+		s.instances = s.instances[excess:]
+	}
+	return nil
+}
+
+// deletePriority orders instances, so the ones to delete first go first.
+func deletePriority(a, b v1alpha1.SAFMachine) int {
+	aProvisioned := ptr.Deref(a.Status.Initialization.Provisioned, false)
+	bProvisioned := ptr.Deref(b.Status.Initialization.Provisioned, false)
+	if aProvisioned != bProvisioned {
+		if bProvisioned {
+			return -1
+		}
+		return 1
+	}
+	return b.CreationTimestamp.Compare(a.CreationTimestamp.Time)
+}
+
+// createInstance creates a SAFMachine from the template of the pool. Labels make MachinePool create a Machine for it.
+func (r *Reconciler) createInstance(ctx context.Context, s *scope) (*v1alpha1.SAFMachine, error) {
+	safmp := s.safMachinePool
+	tmpl := safmp.Spec.Template
+
+	safm := &v1alpha1.SAFMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: safmp.Name + "-",
+			Namespace:    safmp.Namespace,
+			Labels:       map[string]string{},
+			Annotations:  map[string]string{},
+		},
+		Spec: *tmpl.Spec.DeepCopy(),
+	}
+	maps.Copy(safm.Labels, tmpl.ObjectMeta.Labels)
+	maps.Copy(safm.Annotations, tmpl.ObjectMeta.Annotations)
+	safm.Labels[capv1beta2.ClusterNameLabel] = s.machinePool.Spec.ClusterName
+	safm.Labels[capv1beta2.MachinePoolNameLabel] = format.MustFormatValue(s.machinePool.Name)
+	safm.Labels[v1alpha1.SAFMachinePoolNameLabel] = format.MustFormatValue(safmp.Name)
+	// every instance defaults its own provider id
+	safm.Spec.ProviderID = ""
+
+	// not a controller reference, the Machine of the instance becomes its controller
+	if err := controllerutil.SetOwnerReference(safmp, safm, r.Scheme); err != nil {
+		return nil, fmt.Errorf("set owner reference: %w", err)
+	}
+	if err := r.Create(ctx, safm); err != nil {
+		return nil, fmt.Errorf("create saf machine: %w", err)
+	}
+	return safm, nil
+}
+
+// waitForInstancesCreation waits for created instances to show up in the cache, as instances are created
+// with generated names, and the next reconcile, listing stale cache, would create them again.
+func (r *Reconciler) waitForInstancesCreation(ctx context.Context, created []client.ObjectKey) error {
+	if len(created) == 0 {
+		return nil
+	}
+	err := wait.PollUntilContextTimeout(ctx, stateConfirmationInterval, stateConfirmationTimeout, true,
+		func(ctx context.Context) (bool, error) {
+			for _, key := range created {
+				if err := r.Get(ctx, key, &v1alpha1.SAFMachine{}); apierrors.IsNotFound(err) {
+					return false, nil
+				} else if err != nil {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("wait for created saf machines in cache: %w", err)
+	}
+	return nil
+}
+
+// deleteInstance deletes the Machine of the instance, so its node is drained before the host is deprovisioned.
+// Instance without Machine is deleted directly.
+func (r *Reconciler) deleteInstance(ctx context.Context, safm *v1alpha1.SAFMachine) error {
+	ma, err := util.GetOwnerMachine(ctx, r.Client, safm.ObjectMeta)
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("get machine of saf machine %s: %w", safm.Name, err)
+	}
+	if ma != nil {
+		if ma.GetDeletionTimestamp() != nil {
+			return nil
+		}
+		if err := r.Delete(ctx, ma); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("delete machine %s: %w", ma.Name, err)
+		}
+		return nil
+	}
+
+	if err := r.Delete(ctx, safm); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("delete saf machine %s: %w", safm.Name, err)
+	}
+	return nil
+}
+
+// reconcileDelete deletes all instances and removes the finalizer, after they are gone,
+// so hosts are deprovisioned before the MachinePool is deleted.
+func (r *Reconciler) reconcileDelete(ctx context.Context, s *scope) error {
+	l := logf.FromContext(ctx)
+
+	for i := range s.instances {
+		l.Info("delete instance", "safmachine_name", s.instances[i].Name)
+		if err := r.deleteInstance(ctx, &s.instances[i]); err != nil {
+			return err
+		}
+		s.deleting++
+	}
+	s.instances = nil
+
+	if s.deleting > 0 {
+		// will requeue on saf machine deletion
+		l.Info("waiting for instances to be deleted", "deleting", s.deleting)
+		return nil
+	}
+
+	l.Info("instances are deleted, removing finalizer")
+	controllerutil.RemoveFinalizer(s.safMachinePool, v1alpha1.SAFMachinePoolFinalizer)
+	return nil
+}
+
+// syncReplicas reports provisioned instances to the MachinePool.
+// The pool is ready, while all replicas are provisioned, and it is provisioned, once they are provisioned first time.
+func syncReplicas(s *scope) {
+	safmp := s.safMachinePool
+
+	providerIDs := []string{}
+	for _, safm := range s.instances {
+		if ptr.Deref(safm.Status.Initialization.Provisioned, false) && safm.Spec.ProviderID != "" {
+			providerIDs = append(providerIDs, safm.Spec.ProviderID)
+		}
+	}
+	slices.Sort(providerIDs)
+	safmp.Spec.ProviderIDList = providerIDs
+	safmp.Status.Replicas = int32(len(providerIDs))
+
+	safmp.Status.Ready = len(providerIDs) == s.desiredReplicas()
+	if safmp.Status.Ready {
+		safmp.Status.Initialization.Provisioned = ptr.To(true)
+	}
+}
+
+func calculateStatus(ctx context.Context, s *scope) {
+	l := logf.FromContext(ctx)
+	safmp := s.safMachinePool
+
+	setCondition := func(conditionType string, status metav1.ConditionStatus, reason, message string) {
+		conditions.Set(safmp, metav1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: safmp.Generation,
+		})
+	}
+
+	switch {
+	case s.deleting > 0:
+		setCondition(v1alpha1.SAFMachinePoolReplicasProvisionedCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachinePoolScalingDownReason, fmt.Sprintf("Waiting for %d instances to be deleted", s.deleting))
+	case safmp.GetDeletionTimestamp() != nil:
+	case s.machinePool == nil:
+		setCondition(v1alpha1.SAFMachinePoolReplicasProvisionedCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachinePoolWaitingForMachinePoolReason, "Waiting for MachinePool to set owner reference")
+	case int(safmp.Status.Replicas) < s.desiredReplicas():
+		setCondition(v1alpha1.SAFMachinePoolReplicasProvisionedCondition, metav1.ConditionFalse,
+			v1alpha1.SAFMachinePoolScalingUpReason, fmt.Sprintf("%d of %d replicas are provisioned",
+				safmp.Status.Replicas, s.desiredReplicas()))
+	default:
+		setCondition(v1alpha1.SAFMachinePoolReplicasProvisionedCondition, metav1.ConditionTrue,
+			v1alpha1.SAFMachinePoolReplicasProvisionedReason, "")
+	}
+
+	if err := conditions.SetSummaryCondition(safmp, safmp, v1alpha1.SAFMachinePoolReadyCondition,
+		conditions.ForConditionTypes{
+			v1alpha1.SAFMachinePoolReplicasProvisionedCondition,
+		},
+	); err != nil {
+		l.Error(err, "set ready condition")
+	}
+}
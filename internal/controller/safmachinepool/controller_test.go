@@ -0,0 +1,322 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachinepool_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/controller/safmachinepool"
+)
+
+var _ = Describe("SAFMachinePool Controller", func() {
+	newReconciler := func() *safmachinepool.Reconciler {
+		return &safmachinepool.Reconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+	}
+
+	reconcilePool := func(safmp *v1alpha1.SAFMachinePool) {
+		_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safmp)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(safmp), safmp)).To(Succeed())
+	}
+
+	listInstances := func(safmp *v1alpha1.SAFMachinePool) []v1alpha1.SAFMachine {
+		list := &v1alpha1.SAFMachineList{}
+		Expect(k8sClient.List(ctx, list, client.InNamespace(safmp.Namespace),
+			client.MatchingLabels{v1alpha1.SAFMachinePoolNameLabel: safmp.Name})).To(Succeed())
+		return list.Items
+	}
+
+	// createSAFMachinePool creates the SAFMachinePool, which is deleted through the reconciler on cleanup.
+	createSAFMachinePool := func(name string) *v1alpha1.SAFMachinePool {
+		safmp := &v1alpha1.SAFMachinePool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1alpha1.SAFMachinePoolSpec{
+				Template: v1alpha1.SAFMachinePoolMachineTemplate{
+					ObjectMeta: capv1beta2.ObjectMeta{Labels: map[string]string{"role": "worker"}},
+					Spec: v1alpha1.SAFMachineSpec{
+						ProviderID:     "saf://ignored",
+						ProvisionJob:   jobTemplate(),
+						DeprovisionJob: jobTemplate(),
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, safmp)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, safmp))).To(Succeed())
+			for range 2 {
+				_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safmp)})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(safmp), safmp))).To(BeTrue())
+		})
+		return safmp
+	}
+
+	// createOwnerMachinePool creates the MachinePool and sets it as the owner of the SAFMachinePool, like Cluster API does.
+	createOwnerMachinePool := func(safmp *v1alpha1.SAFMachinePool, replicas int32) *capv1beta2.MachinePool {
+		mp := &capv1beta2.MachinePool{
+			ObjectMeta: metav1.ObjectMeta{Name: safmp.Name, Namespace: safmp.Namespace},
+			Spec: capv1beta2.MachinePoolSpec{
+				ClusterName: "test-cluster",
+				Replicas:    ptr.To(replicas),
+				Template: capv1beta2.MachineTemplateSpec{
+					Spec: capv1beta2.MachineSpec{
+						ClusterName: "test-cluster",
+						Bootstrap: capv1beta2.Bootstrap{
+							DataSecretName: ptr.To(safmp.Name + "-bootstrap"),
+						},
+						InfrastructureRef: capv1beta2.ContractVersionedObjectReference{
+							APIGroup: v1alpha1.GroupVersion.Group,
+							Kind:     v1alpha1.SAFMachinePoolKind,
+							Name:     safmp.Name,
+						},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, mp)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, mp))).To(Succeed())
+		})
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(safmp), safmp)).To(Succeed())
+		safmp.OwnerReferences = append(safmp.OwnerReferences, metav1.OwnerReference{
+			APIVersion: capv1beta2.GroupVersion.String(),
+			Kind:       "MachinePool",
+			Name:       mp.Name,
+			UID:        mp.UID,
+		})
+		Expect(k8sClient.Update(ctx, safmp)).To(Succeed())
+		return mp
+	}
+
+	// provisionInstance reports the instance as provisioned, like SAFMachine controller does.
+	provisionInstance := func(safm *v1alpha1.SAFMachine) {
+		safm.Spec.ProviderID = "saf://default/" + safm.Name
+		Expect(k8sClient.Update(ctx, safm)).To(Succeed())
+		safm.Status.Initialization.Provisioned = ptr.To(true)
+		Expect(k8sClient.Status().Update(ctx, safm)).To(Succeed())
+	}
+
+	Context("When MachinePool is scaled", func() {
+		It("should keep as many instances, as MachinePool wants, and report provisioned ones", func() {
+			safmp := createSAFMachinePool("test-scale")
+			mp := createOwnerMachinePool(safmp, 2)
+
+			for range 3 {
+				reconcilePool(safmp)
+			}
+			Expect(safmp.Status.InfrastructureMachineKind).To(Equal(v1alpha1.SAFMachineKind))
+			instances := listInstances(safmp)
+			Expect(instances).To(HaveLen(2))
+			for _, safm := range instances {
+				Expect(safm.Labels).To(HaveKeyWithValue(capv1beta2.ClusterNameLabel, "test-cluster"))
+				Expect(safm.Labels).To(HaveKeyWithValue(capv1beta2.MachinePoolNameLabel, mp.Name))
+				Expect(safm.Labels).To(HaveKeyWithValue("role", "worker"))
+				Expect(safm.OwnerReferences).To(HaveLen(1))
+				Expect(safm.OwnerReferences[0].UID).To(Equal(safmp.UID))
+				Expect(safm.Spec.ProviderID).To(BeEmpty())
+				Expect(safm.Spec.ProvisionJob).To(Equal(jobTemplate()))
+			}
+			Expect(safmp.Status.Replicas).To(BeZero())
+			Expect(safmp.Status.Ready).To(BeFalse())
+			Expect(conditions.GetReason(safmp, v1alpha1.SAFMachinePoolReplicasProvisionedCondition)).
+				To(Equal(v1alpha1.SAFMachinePoolScalingUpReason))
+
+			By("provisioning one instance")
+			provisioned, pending := instances[0], instances[1]
+			provisionInstance(&provisioned)
+			reconcilePool(safmp)
+			Expect(safmp.Spec.ProviderIDList).To(Equal([]string{provisioned.Spec.ProviderID}))
+			Expect(safmp.Status.Replicas).To(Equal(int32(1)))
+			Expect(safmp.Status.Initialization.Provisioned).To(BeNil())
+			Expect(conditions.GetMessage(safmp, v1alpha1.SAFMachinePoolReplicasProvisionedCondition)).
+				To(Equal("1 of 2 replicas are provisioned"))
+
+			By("scaling down, the instance which is not provisioned is deleted through its Machine")
+			machine := &capv1beta2.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: pending.Name, Namespace: "default"},
+				Spec: capv1beta2.MachineSpec{
+					ClusterName: "test-cluster",
+					Bootstrap:   capv1beta2.Bootstrap{DataSecretName: ptr.To("")},
+					InfrastructureRef: capv1beta2.ContractVersionedObjectReference{
+						APIGroup: v1alpha1.GroupVersion.Group,
+						Kind:     v1alpha1.SAFMachineKind,
+						Name:     pending.Name,
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+			pending.OwnerReferences = append(pending.OwnerReferences, metav1.OwnerReference{
+				APIVersion: capv1beta2.GroupVersion.String(),
+				Kind:       "Machine",
+				Name:       machine.Name,
+				UID:        machine.UID,
+				Controller: ptr.To(true),
+			})
+			Expect(k8sClient.Update(ctx, &pending)).To(Succeed())
+
+			mp.Spec.Replicas = ptr.To[int32](1)
+			Expect(k8sClient.Update(ctx, mp)).To(Succeed())
+			reconcilePool(safmp)
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(machine), machine))).To(BeTrue())
+			Expect(safmp.Spec.ProviderIDList).To(Equal([]string{provisioned.Spec.ProviderID}))
+			Expect(safmp.Status.Replicas).To(Equal(int32(1)))
+			Expect(safmp.Status.Ready).To(BeTrue())
+			Expect(safmp.Status.Initialization.Provisioned).To(Equal(ptr.To(true)))
+			Expect(conditions.GetReason(safmp, v1alpha1.SAFMachinePoolReplicasProvisionedCondition)).
+				To(Equal(v1alpha1.SAFMachinePoolScalingDownReason))
+
+			By("deleting the instance, whose Machine is gone")
+			reconcilePool(safmp)
+			Expect(listInstances(safmp)).To(ConsistOf(HaveField("Name", provisioned.Name)))
+			reconcilePool(safmp)
+			Expect(conditions.IsTrue(safmp, v1alpha1.SAFMachinePoolReadyCondition)).To(BeTrue())
+
+			By("scaling up")
+			mp.Spec.Replicas = ptr.To[int32](3)
+			Expect(k8sClient.Update(ctx, mp)).To(Succeed())
+			reconcilePool(safmp)
+			Expect(listInstances(safmp)).To(HaveLen(3))
+			Expect(safmp.Status.Replicas).To(Equal(int32(1)))
+			Expect(safmp.Status.Ready).To(BeFalse())
+			Expect(safmp.Status.Initialization.Provisioned).To(Equal(ptr.To(true)),
+				"provisioned is not reset after initial provisioning")
+			Expect(conditions.GetReason(safmp, v1alpha1.SAFMachinePoolReplicasProvisionedCondition)).
+				To(Equal(v1alpha1.SAFMachinePoolScalingUpReason))
+		})
+	})
+
+	Context("When MachinePool is scaled through cached client", func() {
+		It("should not create instances, that are not in the cache yet, again", func() {
+			safmp := createSAFMachinePool("test-scale-cached")
+			createOwnerMachinePool(safmp, 3)
+
+			By("making a client, that reads from a cache, like the manager's one")
+			informers, err := cache.New(cfg, cache.Options{Scheme: k8sClient.Scheme()})
+			Expect(err).NotTo(HaveOccurred())
+			cacheCtx, cancel := context.WithCancel(ctx)
+			DeferCleanup(cancel)
+			go func() {
+				defer GinkgoRecover()
+				Expect(informers.Start(cacheCtx)).To(Succeed())
+			}()
+			Expect(informers.WaitForCacheSync(cacheCtx)).To(BeTrue())
+			cachedClient, err := client.New(cfg, client.Options{
+				Scheme: k8sClient.Scheme(),
+				Cache:  &client.CacheOptions{Reader: informers},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			controllerReconciler := &safmachinepool.Reconciler{
+				Client: cachedClient,
+				Scheme: cachedClient.Scheme(),
+			}
+
+			Eventually(func(g Gomega) {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safmp)})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(listInstances(safmp)).NotTo(BeEmpty())
+			}).Should(Succeed())
+			for range 3 {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safmp)})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(listInstances(safmp)).To(HaveLen(3))
+		})
+	})
+
+	Context("When SAFMachinePool has no MachinePool", func() {
+		It("should wait for MachinePool to set owner reference", func() {
+			safmp := createSAFMachinePool("test-no-owner")
+
+			for range 3 {
+				reconcilePool(safmp)
+			}
+			Expect(listInstances(safmp)).To(BeEmpty())
+			Expect(safmp.Status.InfrastructureMachineKind).To(Equal(v1alpha1.SAFMachineKind))
+			Expect(conditions.GetReason(safmp, v1alpha1.SAFMachinePoolReplicasProvisionedCondition)).
+				To(Equal(v1alpha1.SAFMachinePoolWaitingForMachinePoolReason))
+		})
+	})
+
+	Context("When SAFMachinePool is deleted", func() {
+		It("should remove finalizer only after instances are gone", func() {
+			safmp := createSAFMachinePool("test-delete")
+			createOwnerMachinePool(safmp, 1)
+			for range 3 {
+				reconcilePool(safmp)
+			}
+			instances := listInstances(safmp)
+			Expect(instances).To(HaveLen(1))
+
+			By("holding the instance with finalizer, like SAFMachine controller does during deprovisioning")
+			safm := instances[0]
+			safm.Finalizers = []string{v1alpha1.SAFMachineFinalizer}
+			Expect(k8sClient.Update(ctx, &safm)).To(Succeed())
+
+			Expect(k8sClient.Delete(ctx, safmp)).To(Succeed())
+			reconcilePool(safmp)
+			Expect(safmp.Finalizers).To(ContainElement(v1alpha1.SAFMachinePoolFinalizer))
+			Expect(conditions.GetReason(safmp, v1alpha1.SAFMachinePoolReplicasProvisionedCondition)).
+				To(Equal(v1alpha1.SAFMachinePoolScalingDownReason))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&safm), &safm)).To(Succeed())
+			Expect(safm.GetDeletionTimestamp()).NotTo(BeNil())
+
+			By("finishing deprovisioning of the instance")
+			safm.Finalizers = nil
+			Expect(k8sClient.Update(ctx, &safm)).To(Succeed())
+			_, err := newReconciler().Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(safmp)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(safmp), safmp))).To(BeTrue())
+		})
+	})
+})
+
+func jobTemplate() v1alpha1.JobTemplate {
+	return v1alpha1.JobTemplate{
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "main",
+							Image: "main",
+						},
+					},
+				},
+			},
+		},
+	}
+}
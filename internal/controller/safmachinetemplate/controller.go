@@ -0,0 +1,241 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package safmachinetemplate reconciles SAFMachineTemplates. It reports capacity of nodes, created from the template,
+// so cluster-autoscaler may scale MachineDeployments from zero, and validates resources referenced by the template.
+package safmachinetemplate
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// Reconciler reconciles a SAFMachineTemplate object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+var controllerName = strings.ToLower(v1alpha1.SAFMachineTemplateKind)
+
+const (
+	// referencesRequeueAfter is how often missing resources, referenced by the template, are checked, until they are found.
+	referencesRequeueAfter = time.Minute
+)
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	l := mgr.GetLogger().WithValues("controller", controllerName, "predicate", "true")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.SAFMachineTemplate{}).
+		Watches(
+			&v1alpha1.SAFHost{},
+			r.safHostHandler(),
+			builder.WithPredicates(predicates.ResourceIsChanged(mgr.GetScheme(), l)),
+		).
+		Named(controllerName).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safmachinetemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safmachinetemplates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=safhosts,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets;configmaps;serviceaccounts,verbs=get
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	tmpl := &v1alpha1.SAFMachineTemplate{}
+	if err := r.Get(ctx, req.NamespacedName, tmpl); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(fmt.Errorf("get saf machine template: %w", err))
+	}
+
+	pacher, err := patch.NewHelper(tmpl, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("make patcher: %w", err)
+	}
+	defer func() {
+		opts := []patch.Option{
+			patch.WithOwnedConditions{Conditions: []string{
+				v1alpha1.SAFMachineTemplateValidCondition,
+			}},
+		}
+		if err := pacher.Patch(ctx, tmpl, opts...); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	if err := r.syncCapacity(ctx, tmpl); err != nil {
+		return ctrl.Result{}, err
+	}
+	return r.validate(ctx, tmpl)
+}
+
+// syncCapacity reports capacity and node info, declared by the template, completed with the inventory of SAFHosts,
+// matching its hostSelector.
+func (r *Reconciler) syncCapacity(ctx context.Context, tmpl *v1alpha1.SAFMachineTemplate) error {
+	hosts, err := r.listHosts(ctx, tmpl)
+	if err != nil {
+		return err
+	}
+
+	capacity, nodeInfo := hostsInventory(hosts)
+	maps.Copy(capacity, tmpl.Spec.Capacity)
+	if declared := tmpl.Spec.NodeInfo; declared != nil {
+		if declared.Architecture != "" {
+			nodeInfo.Architecture = declared.Architecture
+		}
+		if declared.OperatingSystem != "" {
+			nodeInfo.OperatingSystem = declared.OperatingSystem
+		}
+	}
+
+	tmpl.Status.Capacity = nil
+	if len(capacity) > 0 {
+		tmpl.Status.Capacity = capacity
+	}
+	tmpl.Status.NodeInfo = nil
+	if nodeInfo != (v1alpha1.NodeInfo{}) {
+		tmpl.Status.NodeInfo = &nodeInfo
+	}
+	return nil
+}
+
+// listHosts lists SAFHosts, that SAFMachines of the template may claim.
+func (r *Reconciler) listHosts(ctx context.Context, tmpl *v1alpha1.SAFMachineTemplate) ([]v1alpha1.SAFHost, error) {
+	if tmpl.Spec.Template.Spec.HostSelector == nil {
+		return nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(tmpl.Spec.Template.Spec.HostSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parse host selector: %w", err)
+	}
+	hosts := &v1alpha1.SAFHostList{}
+	if err := r.List(ctx, hosts, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("list hosts: %w", err)
+	}
+	return hosts.Items, nil
+}
+
+// hostsInventory returns the smallest capacity of the hosts, so cluster-autoscaler never expects more resources,
+// than a new node gets. Node info is reported only if the hosts agree on it.
+func hostsInventory(hosts []v1alpha1.SAFHost) (corev1.ResourceList, v1alpha1.NodeInfo) {
+	capacity := corev1.ResourceList{}
+	var nodeInfo v1alpha1.NodeInfo
+	architectures := map[v1alpha1.Architecture]struct{}{}
+	operatingSystems := map[v1alpha1.OperatingSystem]struct{}{}
+
+	for _, host := range hosts {
+		if host.GetDeletionTimestamp() != nil {
+			continue
+		}
+		for name, quantity := range host.Spec.Capacity {
+			if current, ok := capacity[name]; !ok || quantity.Cmp(current) < 0 {
+				capacity[name] = quantity
+			}
+		}
+		if host.Spec.NodeInfo != nil {
+			if host.Spec.NodeInfo.Architecture != "" {
+				architectures[host.Spec.NodeInfo.Architecture] = struct{}{}
+				nodeInfo.Architecture = host.Spec.NodeInfo.Architecture
+			}
+			if host.Spec.NodeInfo.OperatingSystem != "" {
+				operatingSystems[host.Spec.NodeInfo.OperatingSystem] = struct{}{}
+				nodeInfo.OperatingSystem = host.Spec.NodeInfo.OperatingSystem
+			}
+		}
+	}
+
+	if len(architectures) > 1 {
+		nodeInfo.Architecture = ""
+	}
+	if len(operatingSystems) > 1 {
+		nodeInfo.OperatingSystem = ""
+	}
+	return capacity, nodeInfo
+}
+
+// safHostHandler enqueues SAFMachineTemplates, whose hostSelector matches the SAFHost before or after the update,
+// so capacity of a template is updated, when a host stops matching it.
+func (r *Reconciler) safHostHandler() handler.EventHandler {
+	enqueue := func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], hosts ...client.Object) {
+		for _, host := range hosts {
+			for _, req := range r.safHostToSAFMachineTemplates(ctx, host) {
+				q.Add(req)
+			}
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+	}
+}
+
+// safHostToSAFMachineTemplates maps the SAFHost to SAFMachineTemplates of any namespace, whose hostSelector matches it.
+func (r *Reconciler) safHostToSAFMachineTemplates(ctx context.Context, o client.Object) []reconcile.Request {
+	l := logf.FromContext(ctx)
+
+	list := &v1alpha1.SAFMachineTemplateList{}
+	if err := r.List(ctx, list); err != nil {
+		l.Error(err, "list saf machine templates", "host_name", o.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, tmpl := range list.Items {
+		if tmpl.Spec.Template.Spec.HostSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(tmpl.Spec.Template.Spec.HostSelector)
+		if err != nil || !selector.Matches(labels.Set(o.GetLabels())) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&tmpl)})
+	}
+	return requests
+}
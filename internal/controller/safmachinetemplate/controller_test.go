@@ -0,0 +1,368 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachinetemplate_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/controller/safmachinetemplate"
+)
+
+var _ = Describe("SAFMachineTemplate Controller", func() {
+	reconcileTemplate := func(tmpl *v1alpha1.SAFMachineTemplate) {
+		controllerReconciler := &safmachinetemplate.Reconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+		}
+		_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(tmpl)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tmpl), tmpl)).To(Succeed())
+	}
+
+	createTemplate := func(name string, spec v1alpha1.SAFMachineTemplateSpec) *v1alpha1.SAFMachineTemplate {
+		tmpl := &v1alpha1.SAFMachineTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       spec,
+		}
+		Expect(k8sClient.Create(ctx, tmpl)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, tmpl)).To(Succeed())
+		})
+		return tmpl
+	}
+
+	createHost := func(name, pool string, capacity corev1.ResourceList, nodeInfo *v1alpha1.NodeInfo) *v1alpha1.SAFHost {
+		host := &v1alpha1.SAFHost{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+			Spec: v1alpha1.SAFHostSpec{
+				Capacity: capacity,
+				NodeInfo: nodeInfo,
+			},
+		}
+		Expect(k8sClient.Create(ctx, host)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, host)).To(Succeed())
+		})
+		return host
+	}
+
+	expectCapacity := func(tmpl *v1alpha1.SAFMachineTemplate, name corev1.ResourceName, quantity string) {
+		GinkgoHelper()
+		actual, ok := tmpl.Status.Capacity[name]
+		Expect(ok).To(BeTrue(), "%s is not reported", name)
+		Expect(actual.Cmp(resource.MustParse(quantity))).To(BeZero(), "%s is %s, expected %s", name, actual.String(), quantity)
+	}
+
+	Context("When hosts match hostSelector of the template", func() {
+		const resourceName = "test-capacity"
+
+		It("should report the smallest capacity of the hosts, completed by the declared one", func() {
+			createHost(resourceName+"-a", resourceName, corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("32Gi"),
+			}, &v1alpha1.NodeInfo{Architecture: "amd64", OperatingSystem: "linux"})
+			createHost(resourceName+"-b", resourceName, corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("16"),
+				corev1.ResourceMemory:           resource.MustParse("16Gi"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("100Gi"),
+			}, &v1alpha1.NodeInfo{Architecture: "amd64"})
+			createHost(resourceName+"-other", "other", corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			}, nil)
+
+			tmpl := createTemplate(resourceName, v1alpha1.SAFMachineTemplateSpec{
+				Template: v1alpha1.SAFMachineTemplateResource{
+					Spec: v1alpha1.SAFMachineSpec{
+						HostSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": resourceName}},
+					},
+				},
+				Capacity: corev1.ResourceList{
+					corev1.ResourcePods: resource.MustParse("110"),
+				},
+			})
+			reconcileTemplate(tmpl)
+
+			Expect(tmpl.Status.Capacity).To(HaveLen(4))
+			expectCapacity(tmpl, corev1.ResourceCPU, "8")
+			expectCapacity(tmpl, corev1.ResourceMemory, "16Gi")
+			expectCapacity(tmpl, corev1.ResourceEphemeralStorage, "100Gi")
+			expectCapacity(tmpl, corev1.ResourcePods, "110")
+			Expect(tmpl.Status.NodeInfo).To(Equal(&v1alpha1.NodeInfo{Architecture: "amd64", OperatingSystem: "linux"}))
+
+			By("adding a host with another architecture")
+			createHost(resourceName+"-c", resourceName, nil, &v1alpha1.NodeInfo{Architecture: "arm64"})
+			reconcileTemplate(tmpl)
+			Expect(tmpl.Status.NodeInfo).To(Equal(&v1alpha1.NodeInfo{OperatingSystem: "linux"}))
+
+			By("declaring capacity and node info on the template")
+			tmpl.Spec.Capacity[corev1.ResourceCPU] = resource.MustParse("4")
+			tmpl.Spec.NodeInfo = &v1alpha1.NodeInfo{Architecture: "arm64"}
+			Expect(k8sClient.Update(ctx, tmpl)).To(Succeed())
+			reconcileTemplate(tmpl)
+			expectCapacity(tmpl, corev1.ResourceCPU, "4")
+			expectCapacity(tmpl, corev1.ResourceMemory, "16Gi")
+			Expect(tmpl.Status.NodeInfo).To(Equal(&v1alpha1.NodeInfo{Architecture: "arm64", OperatingSystem: "linux"}))
+		})
+	})
+
+	Context("When template has no hostSelector", func() {
+		const resourceName = "test-declared-capacity"
+
+		It("should report only the declared capacity", func() {
+			tmpl := createTemplate(resourceName, v1alpha1.SAFMachineTemplateSpec{})
+			reconcileTemplate(tmpl)
+			Expect(tmpl.Status.Capacity).To(BeEmpty())
+			Expect(tmpl.Status.NodeInfo).To(BeNil())
+
+			tmpl.Spec.Capacity = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")}
+			tmpl.Spec.NodeInfo = &v1alpha1.NodeInfo{Architecture: "amd64", OperatingSystem: "linux"}
+			Expect(k8sClient.Update(ctx, tmpl)).To(Succeed())
+			reconcileTemplate(tmpl)
+			Expect(tmpl.Status.Capacity).To(HaveLen(1))
+			expectCapacity(tmpl, corev1.ResourceMemory, "8Gi")
+			Expect(tmpl.Status.NodeInfo).To(Equal(&v1alpha1.NodeInfo{Architecture: "amd64", OperatingSystem: "linux"}))
+		})
+	})
+
+	Context("When jobs of the template reference resources", func() {
+		const resourceName = "test-references"
+
+		It("should report missing resources in Valid condition", func() {
+			provisionJob := v1alpha1.JobTemplate{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				ServiceAccountName: resourceName,
+				RestartPolicy:      corev1.RestartPolicyNever,
+				Containers: []corev1.Container{{
+					Name:  "provision",
+					Image: "busybox",
+					EnvFrom: []corev1.EnvFromSource{{
+						ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: resourceName}},
+					}},
+				}},
+				Volumes: []corev1.Volume{
+					{Name: "ssh-key", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: resourceName}}},
+					{Name: "extra", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+						SecretName: resourceName + "-optional",
+						Optional:   ptr.To(true),
+					}}},
+				},
+			}}}}
+			deprovisionJob := v1alpha1.JobTemplate{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers: []corev1.Container{{
+					Name:  "deprovision",
+					Image: "busybox",
+					Env: []corev1.EnvVar{{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: resourceName},
+						Key:                  "token",
+					}}}},
+				}},
+			}}}}
+			tmpl := createTemplate(resourceName, v1alpha1.SAFMachineTemplateSpec{
+				Template: v1alpha1.SAFMachineTemplateResource{
+					Spec: v1alpha1.SAFMachineSpec{
+						ProvisionJob:   provisionJob,
+						DeprovisionJob: deprovisionJob,
+					},
+				},
+			})
+			reconcileTemplate(tmpl)
+
+			Expect(conditions.IsFalse(tmpl, v1alpha1.SAFMachineTemplateValidCondition)).To(BeTrue())
+			Expect(conditions.GetReason(tmpl, v1alpha1.SAFMachineTemplateValidCondition)).
+				To(Equal(v1alpha1.SAFMachineTemplateMissingReferencesReason))
+			Expect(conditions.GetMessage(tmpl, v1alpha1.SAFMachineTemplateValidCondition)).To(Equal(
+				"* ConfigMap test-references referenced by provisionJob is not found\n" +
+					"* Secret test-references referenced by provisionJob is not found\n" +
+					"* ServiceAccount test-references referenced by provisionJob is not found\n" +
+					"* Secret test-references referenced by deprovisionJob is not found"))
+
+			By("creating referenced resources")
+			for _, obj := range []client.Object{
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"}},
+			} {
+				Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+				DeferCleanup(func() {
+					Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+				})
+			}
+			reconcileTemplate(tmpl)
+			Expect(conditions.IsTrue(tmpl, v1alpha1.SAFMachineTemplateValidCondition)).To(BeTrue())
+		})
+	})
+
+	Context("When the template references Secrets outside of provision and deprovision jobs", func() {
+		// expectMissingSecret checks, that the template is invalid, until the Secret is created.
+		expectMissingSecret := func(tmpl *v1alpha1.SAFMachineTemplate, secretName, message string) {
+			GinkgoHelper()
+			reconcileTemplate(tmpl)
+			Expect(conditions.IsFalse(tmpl, v1alpha1.SAFMachineTemplateValidCondition)).To(BeTrue())
+			Expect(conditions.GetMessage(tmpl, v1alpha1.SAFMachineTemplateValidCondition)).To(Equal(message))
+
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"}}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			})
+			reconcileTemplate(tmpl)
+			Expect(conditions.IsTrue(tmpl, v1alpha1.SAFMachineTemplateValidCondition)).To(BeTrue())
+		}
+
+		It("should report missing Secret referenced by inspectionJob", func() {
+			const resourceName = "test-references-inspection"
+			tmpl := createTemplate(resourceName, v1alpha1.SAFMachineTemplateSpec{
+				Template: v1alpha1.SAFMachineTemplateResource{
+					Spec: v1alpha1.SAFMachineSpec{
+						InspectionJob: &v1alpha1.JobTemplate{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+							RestartPolicy:    corev1.RestartPolicyNever,
+							ImagePullSecrets: []corev1.LocalObjectReference{{Name: resourceName}},
+							Containers:       []corev1.Container{{Name: "inspect", Image: "busybox"}},
+						}}}},
+					},
+				},
+			})
+			expectMissingSecret(tmpl, resourceName, "* Secret test-references-inspection referenced by inspectionJob is not found")
+		})
+
+		It("should report missing Secret with ssh key", func() {
+			const resourceName = "test-references-ssh"
+			tmpl := createTemplate(resourceName, v1alpha1.SAFMachineTemplateSpec{
+				Template: v1alpha1.SAFMachineTemplateResource{
+					Spec: v1alpha1.SAFMachineSpec{
+						SSH: &v1alpha1.SSHProvisioner{
+							Host: "10.0.0.1",
+							KeySecretRef: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: resourceName},
+								Key:                  "id_ed25519",
+							},
+						},
+					},
+				},
+			})
+			expectMissingSecret(tmpl, resourceName, "* Secret test-references-ssh referenced by ssh is not found")
+		})
+
+		It("should report missing Secrets of connection config", func() {
+			const resourceName = "test-references-connection"
+			tmpl := createTemplate(resourceName, v1alpha1.SAFMachineTemplateSpec{
+				Template: v1alpha1.SAFMachineTemplateResource{
+					Spec: v1alpha1.SAFMachineSpec{
+						ConnectionConfigFrom: []v1alpha1.ConnectionConfigSource{
+							{
+								Name: "password",
+								ValueFrom: &v1alpha1.ConnectionConfigValueSource{SecretKeyRef: corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: resourceName + "-value"},
+									Key:                  "password",
+								}},
+							},
+							{SecretRef: &corev1.LocalObjectReference{Name: resourceName + "-all"}},
+							{
+								Name: "token",
+								ValueFrom: &v1alpha1.ConnectionConfigValueSource{SecretKeyRef: corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: resourceName + "-optional"},
+									Key:                  "token",
+									Optional:             ptr.To(true),
+								}},
+							},
+						},
+					},
+				},
+			})
+			reconcileTemplate(tmpl)
+			Expect(conditions.GetMessage(tmpl, v1alpha1.SAFMachineTemplateValidCondition)).To(Equal(
+				"* Secret test-references-connection-all referenced by connectionConfigFrom is not found\n" +
+					"* Secret test-references-connection-value referenced by connectionConfigFrom is not found"))
+
+			By("creating the Secret exposed as a whole")
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-all", Namespace: "default"}}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			})
+			expectMissingSecret(tmpl, resourceName+"-value",
+				"* Secret test-references-connection-value referenced by connectionConfigFrom is not found")
+		})
+	})
+
+	Context("When a host is relabeled, so it stops matching the template", func() {
+		const resourceName = "test-relabel"
+
+		It("should update capacity of the template through the SAFHost watch", func() {
+			By("running the controller in a manager")
+			mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+				Scheme:     k8sClient.Scheme(),
+				Metrics:    metricsserver.Options{BindAddress: "0"},
+				Controller: config.Controller{SkipNameValidation: ptr.To(true)},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&safmachinetemplate.Reconciler{
+				Client: mgr.GetClient(),
+				Scheme: mgr.GetScheme(),
+			}).SetupWithManager(mgr)).To(Succeed())
+			mgrCtx, cancel := context.WithCancel(ctx)
+			DeferCleanup(cancel)
+			go func() {
+				defer GinkgoRecover()
+				Expect(mgr.Start(mgrCtx)).To(Succeed())
+			}()
+
+			createHost(resourceName+"-small", resourceName, corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("2"),
+			}, nil)
+			createHost(resourceName+"-large", resourceName, corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("8"),
+			}, nil)
+			tmpl := createTemplate(resourceName, v1alpha1.SAFMachineTemplateSpec{
+				Template: v1alpha1.SAFMachineTemplateResource{
+					Spec: v1alpha1.SAFMachineSpec{
+						HostSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": resourceName}},
+					},
+				},
+			})
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tmpl), tmpl)).To(Succeed())
+				g.Expect(tmpl.Status.Capacity).To(HaveKeyWithValue(corev1.ResourceCPU, resource.MustParse("2")))
+			}).Should(Succeed())
+
+			By("moving the small host to another pool")
+			host := &v1alpha1.SAFHost{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: resourceName + "-small"}, host)).To(Succeed())
+			host.Labels["pool"] = "other"
+			Expect(k8sClient.Update(ctx, host)).To(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tmpl), tmpl)).To(Succeed())
+				g.Expect(tmpl.Status.Capacity).To(HaveKeyWithValue(corev1.ResourceCPU, resource.MustParse("8")))
+			}).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,201 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachinetemplate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// reference is a resource, that pods of a job can't start without.
+type reference struct {
+	kind string
+	name string
+}
+
+// validate checks, that resources referenced by jobs, ssh and connection config of the template exist, as pods
+// of the jobs would be stuck without them long after the template is used. They are not watched, so missing ones
+// are checked periodically.
+func (r *Reconciler) validate(ctx context.Context, tmpl *v1alpha1.SAFMachineTemplate) (ctrl.Result, error) {
+	l := logf.FromContext(ctx, "phase", "validate")
+	spec := &tmpl.Spec.Template.Spec
+
+	type source struct {
+		name string
+		refs []reference
+	}
+	sources := []source{
+		{name: "provisionJob", refs: podSpecReferences(&spec.ProvisionJob.Spec.Template.Spec)},
+		{name: "deprovisionJob", refs: podSpecReferences(&spec.DeprovisionJob.Spec.Template.Spec)},
+	}
+	if spec.InspectionJob != nil {
+		sources = append(sources, source{name: "inspectionJob", refs: podSpecReferences(&spec.InspectionJob.Spec.Template.Spec)})
+	}
+	if spec.SSH != nil && !ptr.Deref(spec.SSH.KeySecretRef.Optional, false) {
+		sources = append(sources, source{name: "ssh", refs: []reference{{kind: "Secret", name: spec.SSH.KeySecretRef.Name}}})
+	}
+	sources = append(sources, source{name: "connectionConfigFrom", refs: connectionConfigReferences(spec.ConnectionConfigFrom)})
+
+	var missing []string
+	for _, src := range sources {
+		for _, ref := range src.refs {
+			found, err := r.exists(ctx, types.NamespacedName{Namespace: tmpl.Namespace, Name: ref.name}, ref.kind)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("get %s %s referenced by %s: %w", ref.kind, ref.name, src.name, err)
+			}
+			if !found {
+				missing = append(missing, fmt.Sprintf("* %s %s referenced by %s is not found", ref.kind, ref.name, src.name))
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		l.Info("template references missing resources", "missing", missing)
+		conditions.Set(tmpl, metav1.Condition{
+			Type:    v1alpha1.SAFMachineTemplateValidCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.SAFMachineTemplateMissingReferencesReason,
+			Message: strings.Join(missing, "\n"),
+		})
+		return ctrl.Result{RequeueAfter: referencesRequeueAfter}, nil
+	}
+
+	conditions.Set(tmpl, metav1.Condition{
+		Type:   v1alpha1.SAFMachineTemplateValidCondition,
+		Status: metav1.ConditionTrue,
+		Reason: v1alpha1.SAFMachineTemplateValidReason,
+	})
+	return ctrl.Result{}, nil
+}
+
+// exists checks if the referenced resource exists in the namespace of the template.
+func (r *Reconciler) exists(ctx context.Context, key types.NamespacedName, kind string) (bool, error) {
+	var obj client.Object
+	switch kind {
+	case "Secret":
+		obj = &corev1.Secret{}
+	case "ConfigMap":
+		obj = &corev1.ConfigMap{}
+	case "ServiceAccount":
+		obj = &corev1.ServiceAccount{}
+	default:
+		return false, fmt.Errorf("unknown kind %s", kind)
+	}
+
+	if err := r.Get(ctx, key, obj); apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// podSpecReferences returns sorted Secrets, ConfigMaps and ServiceAccounts, that pods of the spec require.
+// References, marked as optional, are skipped.
+func podSpecReferences(spec *corev1.PodSpec) []reference {
+	var refs []reference
+	add := func(kind, name string, optional *bool) {
+		if name != "" && !ptr.Deref(optional, false) {
+			refs = append(refs, reference{kind: kind, name: name})
+		}
+	}
+
+	add("ServiceAccount", spec.ServiceAccountName, nil)
+	for _, secret := range spec.ImagePullSecrets {
+		add("Secret", secret.Name, nil)
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			add("Secret", volume.Secret.SecretName, volume.Secret.Optional)
+		}
+		if volume.ConfigMap != nil {
+			add("ConfigMap", volume.ConfigMap.Name, volume.ConfigMap.Optional)
+		}
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil {
+				add("Secret", source.Secret.Name, source.Secret.Optional)
+			}
+			if source.ConfigMap != nil {
+				add("ConfigMap", source.ConfigMap.Name, source.ConfigMap.Optional)
+			}
+		}
+	}
+
+	for _, container := range slices.Concat(spec.InitContainers, spec.Containers) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				add("Secret", envFrom.SecretRef.Name, envFrom.SecretRef.Optional)
+			}
+			if envFrom.ConfigMapRef != nil {
+				add("ConfigMap", envFrom.ConfigMapRef.Name, envFrom.ConfigMapRef.Optional)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				add("Secret", ref.Name, ref.Optional)
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				add("ConfigMap", ref.Name, ref.Optional)
+			}
+		}
+	}
+
+	return sortReferences(refs)
+}
+
+// connectionConfigReferences returns sorted Secrets, that connection config is taken from.
+func connectionConfigReferences(sources []v1alpha1.ConnectionConfigSource) []reference {
+	var refs []reference
+	for _, source := range sources {
+		if source.ValueFrom != nil && !ptr.Deref(source.ValueFrom.SecretKeyRef.Optional, false) {
+			refs = append(refs, reference{kind: "Secret", name: source.ValueFrom.SecretKeyRef.Name})
+		}
+		if source.SecretRef != nil && source.SecretRef.Name != "" {
+			refs = append(refs, reference{kind: "Secret", name: source.SecretRef.Name})
+		}
+	}
+	return sortReferences(refs)
+}
+
+// sortReferences sorts references and removes duplicates.
+func sortReferences(refs []reference) []reference {
+	slices.SortFunc(refs, func(a, b reference) int {
+		return strings.Compare(a.kind+"/"+a.name, b.kind+"/"+b.name)
+	})
+	return slices.Compact(refs)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package movestate keeps status of SAF objects in their annotation. clusterctl move creates objects
+// in the target management cluster without status, so controllers restore it from the annotation,
+// instead of provisioning live hosts again.
+package movestate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// state is the content of the annotation. Identity of the object, that saved the status, tells moved objects
+// from the object itself and from objects, the annotation is copied to, e.g. by kubectl.
+type state struct {
+	// UID of the object, that saved the status. It changes, when clusterctl move recreates the object.
+	UID       types.UID       `json:"uid"`
+	Namespace string          `json:"namespace,omitempty"`
+	Name      string          `json:"name"`
+	Status    json.RawMessage `json:"status"`
+}
+
+// Save stores the status in the annotation of the object, unless it is already there.
+// Callers drop conditions and other fields, that are recalculated on every reconciliation.
+func Save(obj metav1.Object, status any) error {
+	statusData, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("marshal status: %w", err)
+	}
+	data, err := json.Marshal(state{
+		UID:       obj.GetUID(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Status:    statusData,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal status annotation: %w", err)
+	}
+	if obj.GetAnnotations()[v1alpha1.StatusAnnotation] == string(data) {
+		return nil
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[v1alpha1.StatusAnnotation] = string(data)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// Restore reads the status from the annotation of the object, moved from another management cluster,
+// and removes the annotation, so it is restored once. It reports false, if the object has no annotation,
+// the annotation is saved by the object itself or it belongs to another object.
+func Restore(obj metav1.Object, status any) (bool, error) {
+	data, ok := obj.GetAnnotations()[v1alpha1.StatusAnnotation]
+	if !ok {
+		return false, nil
+	}
+	saved := state{}
+	if err := json.Unmarshal([]byte(data), &saved); err != nil {
+		return false, fmt.Errorf("unmarshal status annotation: %w", err)
+	}
+	if saved.UID == obj.GetUID() {
+		return false, nil
+	}
+	annotations := obj.GetAnnotations()
+	delete(annotations, v1alpha1.StatusAnnotation)
+	obj.SetAnnotations(annotations)
+	if saved.Namespace != obj.GetNamespace() || saved.Name != obj.GetName() {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(saved.Status, status); err != nil {
+		return false, fmt.Errorf("unmarshal status annotation: %w", err)
+	}
+	return true, nil
+}
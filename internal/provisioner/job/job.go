@@ -0,0 +1,745 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package job provisions hosts by Jobs, built from SAFMachine's job templates or generated for ssh.
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
+)
+
+// DefaultConnectionConfigEnvPrefix is the default of Provisioner.ConnectionConfigEnvPrefix.
+const DefaultConnectionConfigEnvPrefix = "SAF_"
+
+// Provisioner runs provision and deprovision jobs, owned by the SAFMachine or the SAFCluster.
+// Jobs report provision result through termination messages of their containers.
+type Provisioner struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// APIReader reads pods of jobs directly from the API server, as the manager doesn't cache pods.
+	// Client is used, if it is nil.
+	APIReader client.Reader
+
+	// ConnectionConfigEnvPrefix is prepended to names of env, that expose SAFMachine's connection config to jobs.
+	ConnectionConfigEnvPrefix string
+
+	// SSHImage is the default image of jobs, that provision hosts over ssh.
+	SSHImage string
+}
+
+var _ provisioner.Provisioner = &Provisioner{}
+var _ provisioner.Inspector = &Provisioner{}
+var _ provisioner.ClusterProvisioner = &Provisioner{}
+var _ provisioner.Cleaner = &Provisioner{}
+
+// Provision creates the provision job of the attempt, unless it exists.
+func (p *Provisioner) Provision(ctx context.Context, req provisioner.Request) (provisioner.Status, error) {
+	return p.ensureJob(ctx, req, provisioner.OperationProvision)
+}
+
+// Deprovision creates the deprovision job, unless it exists.
+func (p *Provisioner) Deprovision(ctx context.Context, req provisioner.Request) (provisioner.Status, error) {
+	return p.ensureJob(ctx, req, provisioner.OperationDeprovision)
+}
+
+// Inspect creates the inspection job, unless it exists.
+func (p *Provisioner) Inspect(ctx context.Context, req provisioner.Request) (provisioner.Status, error) {
+	return p.ensureJob(ctx, req, provisioner.OperationInspect)
+}
+
+// Status reports the status of the job of the operation.
+func (p *Provisioner) Status(ctx context.Context, req provisioner.Request, op provisioner.Operation) (provisioner.Status, error) {
+	job, err := p.getJob(ctx, req, op)
+	if err != nil || job == nil {
+		return provisioner.Status{State: provisioner.StateNotStarted, Name: jobName(req, op)}, err
+	}
+	return p.jobStatus(ctx, job, op)
+}
+
+// Cancel deletes the unfinished job of the operation together with its pods.
+func (p *Provisioner) Cancel(ctx context.Context, req provisioner.Request, op provisioner.Operation) error {
+	job, err := p.getJob(ctx, req, op)
+	if err != nil || job == nil {
+		return err
+	}
+	return p.cancelJob(ctx, job)
+}
+
+func (p *Provisioner) cancelJob(ctx context.Context, job *batchv1.Job) error {
+	l := logf.FromContext(ctx)
+
+	if _, finished := jobFinished(job); finished || job.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	l.Info("delete unfinished job", "job_name", job.Name)
+	err := p.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground))
+	return client.IgnoreNotFound(err)
+}
+
+func (p *Provisioner) getJob(ctx context.Context, req provisioner.Request, op provisioner.Operation) (*batchv1.Job, error) {
+	return p.getJobByKey(ctx, types.NamespacedName{Namespace: req.SAFMachine.Namespace, Name: jobName(req, op)})
+}
+
+func (p *Provisioner) getJobByKey(ctx context.Context, key types.NamespacedName) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+	if err := p.Get(ctx, key, job); apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("get job %s: %w", key.Name, err)
+	}
+	return job, nil
+}
+
+func (p *Provisioner) ensureJob(ctx context.Context, req provisioner.Request, op provisioner.Operation) (provisioner.Status, error) {
+	l := logf.FromContext(ctx)
+
+	if job, err := p.getJob(ctx, req, op); err != nil {
+		return provisioner.Status{}, err
+	} else if job != nil {
+		return p.jobStatus(ctx, job, op)
+	}
+
+	provision := op == provisioner.OperationProvision
+	tmpl, err := p.jobTemplate(req, op)
+	if err != nil {
+		return provisioner.Status{}, err
+	}
+	if op == provisioner.OperationInspect {
+		// inspection doesn't need bootstrap data
+		req.Machine = nil
+	}
+	job, err := p.newJob(ctx, req, jobName(req, op), tmpl)
+	if err != nil {
+		return provisioner.Status{}, err
+	}
+
+	if policy := req.SAFMachine.Spec.RetryPolicy; provision && policy != nil && tmpl.Spec.BackoffLimit == nil {
+		switch policy.Strategy {
+		case v1alpha1.JobBackoffRetryStrategy:
+			job.Spec.BackoffLimit = ptr.To(max(policy.MaxAttempts, 1) - 1)
+		case v1alpha1.RecreateJobRetryStrategy, "":
+			// controller retries by itself
+			job.Spec.BackoffLimit = ptr.To[int32](0)
+		}
+	}
+
+	l.Info("create job", "job_name", job.Name, "operation", op, "attempt", req.Attempt)
+	if err := p.Create(ctx, job); client.IgnoreAlreadyExists(err) != nil {
+		return provisioner.Status{}, fmt.Errorf("create job %s: %w", job.Name, err)
+	}
+
+	return provisioner.Status{State: provisioner.StateRunning, Name: job.Name, StartTime: metav1.Now()}, nil
+}
+
+// jobStatus describes the job as provisioner status. Result of provisioning is read from pods of succeeded job.
+func (p *Provisioner) jobStatus(ctx context.Context, job *batchv1.Job, op provisioner.Operation) (provisioner.Status, error) {
+	status := provisioner.Status{
+		State:     provisioner.StateRunning,
+		Name:      job.Name,
+		StartTime: job.CreationTimestamp,
+	}
+
+	condition, finished := jobFinished(job)
+	switch {
+	case !finished:
+		status.Canceling = job.GetDeletionTimestamp() != nil
+		return status, nil
+	case condition.Type == batchv1.JobFailed:
+		status.State = provisioner.StateFailed
+		status.Message = condition.Message
+	default:
+		status.State = provisioner.StateSucceeded
+	}
+	status.CompletionTime = ptr.To(condition.LastTransitionTime)
+
+	if status.State == provisioner.StateSucceeded && op == provisioner.OperationProvision {
+		result, err := p.provisionResult(ctx, job)
+		if err != nil {
+			return status, err
+		}
+		status.Result = &result
+	}
+	if status.State == provisioner.StateSucceeded && op == provisioner.OperationProvisionCluster {
+		result, err := p.clusterProvisionResult(ctx, job)
+		if err != nil {
+			return status, err
+		}
+		status.ClusterResult = &result
+	}
+	if status.State == provisioner.StateSucceeded && op == provisioner.OperationInspect {
+		facts, err := p.hostFacts(ctx, job)
+		if err != nil {
+			return status, err
+		}
+		status.Facts = &facts
+	}
+
+	return status, nil
+}
+
+// provisionResult collects results, reported by succeeded containers of the job through termination messages.
+func (p *Provisioner) provisionResult(ctx context.Context, job *batchv1.Job) (v1alpha1.ProvisionResult, error) {
+	result := v1alpha1.ProvisionResult{}
+	err := p.terminationMessages(ctx, job, func(message []byte) error {
+		containerResult := v1alpha1.ProvisionResult{}
+		if err := json.Unmarshal(message, &containerResult); err != nil {
+			return fmt.Errorf("parse provision result: %w", err)
+		}
+		mergeProvisionResult(&result, containerResult)
+		return nil
+	})
+	return result, err
+}
+
+// clusterProvisionResult collects results, reported by succeeded containers of the cluster job through termination messages.
+func (p *Provisioner) clusterProvisionResult(ctx context.Context, job *batchv1.Job) (v1alpha1.SAFClusterProvisionResult, error) {
+	result := v1alpha1.SAFClusterProvisionResult{}
+	err := p.terminationMessages(ctx, job, func(message []byte) error {
+		containerResult := v1alpha1.SAFClusterProvisionResult{}
+		if err := json.Unmarshal(message, &containerResult); err != nil {
+			return fmt.Errorf("parse cluster provision result: %w", err)
+		}
+		if !containerResult.ControlPlaneEndpoint.IsZero() {
+			result.ControlPlaneEndpoint = containerResult.ControlPlaneEndpoint
+		}
+		return nil
+	})
+	return result, err
+}
+
+// hostFacts collects facts, reported by succeeded containers of the job through termination messages.
+func (p *Provisioner) hostFacts(ctx context.Context, job *batchv1.Job) (v1alpha1.HostFacts, error) {
+	facts := v1alpha1.HostFacts{}
+	err := p.terminationMessages(ctx, job, func(message []byte) error {
+		containerFacts := v1alpha1.HostFacts{}
+		if err := json.Unmarshal(message, &containerFacts); err != nil {
+			return fmt.Errorf("parse host facts: %w", err)
+		}
+		mergeHostFacts(&facts, containerFacts)
+		return nil
+	})
+	return facts, err
+}
+
+// terminationMessages passes termination messages of containers of a succeeded pod of the job to parse.
+func (p *Provisioner) terminationMessages(ctx context.Context, job *batchv1.Job, parse func(message []byte) error) error {
+	l := logf.FromContext(ctx)
+
+	reader := p.APIReader
+	if reader == nil {
+		reader = p.Client
+	}
+	pods := &corev1.PodList{}
+	if err := reader.List(ctx, pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		return fmt.Errorf("list pods of job %s: %w", job.Name, err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Terminated == nil || cs.State.Terminated.Message == "" {
+				continue
+			}
+			if err := parse([]byte(cs.State.Terminated.Message)); err != nil {
+				return fmt.Errorf("pod %s container %s: %w", pod.Name, cs.Name, err)
+			}
+			l.Info("got termination message", "pod_name", pod.Name, "container_name", cs.Name)
+		}
+		// single succeeded pod is enough
+		break
+	}
+
+	return nil
+}
+
+func mergeProvisionResult(dst *v1alpha1.ProvisionResult, src v1alpha1.ProvisionResult) {
+	if src.ProviderID != "" {
+		dst.ProviderID = src.ProviderID
+	}
+	if src.Hostname != "" {
+		dst.Hostname = src.Hostname
+	}
+	if src.FailureDomain != "" {
+		dst.FailureDomain = src.FailureDomain
+	}
+	dst.Addresses = append(dst.Addresses, src.Addresses...)
+}
+
+func mergeHostFacts(dst *v1alpha1.HostFacts, src v1alpha1.HostFacts) {
+	if src.Architecture != "" {
+		dst.Architecture = src.Architecture
+	}
+	if src.OS != "" {
+		dst.OS = src.OS
+	}
+	if src.CPUs != 0 {
+		dst.CPUs = src.CPUs
+	}
+	if src.Memory != nil {
+		dst.Memory = src.Memory
+	}
+	dst.Disks = append(dst.Disks, src.Disks...)
+	dst.NICs = append(dst.NICs, src.NICs...)
+}
+
+// jobTemplate returns the template of the job of the operation with the SAFMachine.
+// SSH of the SAFMachine wins over its job templates, they win over SSH of the claimed host.
+func (p *Provisioner) jobTemplate(req provisioner.Request, op provisioner.Operation) (v1alpha1.JobTemplate, error) {
+	safm := req.SAFMachine
+	provision := op == provisioner.OperationProvision
+	tmpl := safm.Spec.DeprovisionJob
+	switch op {
+	case provisioner.OperationProvision:
+		tmpl = safm.Spec.ProvisionJob
+	case provisioner.OperationInspect:
+		if safm.Spec.InspectionJob == nil {
+			return tmpl, fmt.Errorf("SAFMachine %s has no inspection job", safm.Name)
+		}
+		return *safm.Spec.InspectionJob, nil
+	}
+
+	switch {
+	case safm.Spec.SSH != nil:
+		return p.sshJobTemplate(safm.Spec.SSH, provision), nil
+	case len(tmpl.Spec.Template.Spec.Containers) > 0:
+		return tmpl, nil
+	case req.Host != nil && req.Host.Spec.SSH != nil:
+		return p.sshJobTemplate(req.Host.Spec.SSH, provision), nil
+	}
+	return tmpl, fmt.Errorf("neither SAFMachine %s nor its host define how to provision it", safm.Name)
+}
+
+// newJob builds a Job owned by the SAFMachine from the given template.
+// Bootstrap data is mounted to /etc/bootstrap/ if the owner Machine has it.
+// Job runs on nodes of the failure domain, the SAFMachine is placed into.
+func (p *Provisioner) newJob(ctx context.Context, req provisioner.Request, name string, tmpl v1alpha1.JobTemplate) (*batchv1.Job, error) {
+	safm := req.SAFMachine
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: safm.Namespace,
+			Labels: map[string]string{
+				v1alpha1.SAFMachineNameLabel: safm.Name,
+			},
+		},
+		Spec: *tmpl.Spec.DeepCopy(),
+	}
+
+	if job.Spec.Template.Labels == nil {
+		job.Spec.Template.Labels = map[string]string{}
+	}
+	job.Spec.Template.Labels[v1alpha1.SAFMachineNameLabel] = safm.Name
+
+	if req.Machine != nil && req.Machine.Spec.Bootstrap.DataSecretName != nil {
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: v1alpha1.BootstrapVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: *req.Machine.Spec.Bootstrap.DataSecretName,
+					// bootstrap secret may be already gone, when machine is deleting
+					Optional: ptr.To(safm.GetDeletionTimestamp() != nil),
+				},
+			},
+		})
+
+		containers := job.Spec.Template.Spec.Containers
+		for i := range containers {
+			containers[i].VolumeMounts = append(containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      v1alpha1.BootstrapVolumeName,
+				ReadOnly:  true,
+				MountPath: v1alpha1.BootstrapMountPath,
+			})
+		}
+	}
+
+	if req.FailureDomain != nil && len(req.FailureDomain.JobNodeSelector) > 0 {
+		// node selector of the template wins
+		nodeSelector := maps.Clone(req.FailureDomain.JobNodeSelector)
+		maps.Copy(nodeSelector, job.Spec.Template.Spec.NodeSelector)
+		job.Spec.Template.Spec.NodeSelector = nodeSelector
+	}
+
+	if err := p.completeJob(ctx, req, safm, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Clean runs the cleaning job of the SAFHost, named after its status.cleaningJobName
+// in its status.cleaningJobNamespace, and reports its status.
+func (p *Provisioner) Clean(ctx context.Context, host *v1alpha1.SAFHost) (provisioner.Status, error) {
+	l := logf.FromContext(ctx)
+
+	if host.Status.CleaningJobNamespace == "" {
+		return provisioner.Status{}, fmt.Errorf("host %s has no namespace for cleaning jobs", host.Name)
+	}
+	key := types.NamespacedName{Namespace: host.Status.CleaningJobNamespace, Name: host.Status.CleaningJobName}
+	job := &batchv1.Job{}
+	if err := p.Get(ctx, key, job); err == nil {
+		return p.jobStatus(ctx, job, provisioner.OperationClean)
+	} else if !apierrors.IsNotFound(err) {
+		return provisioner.Status{}, fmt.Errorf("get job %s: %w", key.Name, err)
+	}
+
+	job, err := p.newCleaningJob(ctx, host, key.Name)
+	if err != nil {
+		return provisioner.Status{}, err
+	}
+	l.Info("create job", "job_name", job.Name, "operation", provisioner.OperationClean)
+	if err := p.Create(ctx, job); client.IgnoreAlreadyExists(err) != nil {
+		return provisioner.Status{}, fmt.Errorf("create job %s: %w", job.Name, err)
+	}
+	return provisioner.Status{State: provisioner.StateRunning, Name: job.Name, StartTime: metav1.Now()}, nil
+}
+
+// newCleaningJob builds a Job owned by the SAFHost from its cleaning job template.
+func (p *Provisioner) newCleaningJob(ctx context.Context, host *v1alpha1.SAFHost, name string) (*batchv1.Job, error) {
+	if host.Spec.CleaningJob == nil {
+		return nil, fmt.Errorf("host %s has no cleaning job", host.Name)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: host.Status.CleaningJobNamespace,
+			Labels: map[string]string{
+				v1alpha1.SAFHostNameLabel: host.Name,
+			},
+		},
+		Spec: *host.Spec.CleaningJob.Spec.DeepCopy(),
+	}
+
+	if job.Spec.Template.Labels == nil {
+		job.Spec.Template.Labels = map[string]string{}
+	}
+	job.Spec.Template.Labels[v1alpha1.SAFHostNameLabel] = host.Name
+
+	if err := p.completeJob(ctx, provisioner.Request{Host: host}, host, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ProvisionCluster creates the provision job of the SAFCluster, unless it exists.
+func (p *Provisioner) ProvisionCluster(ctx context.Context, safcl *v1alpha1.SAFCluster) (provisioner.Status, error) {
+	return p.ensureClusterJob(ctx, safcl, provisioner.OperationProvisionCluster)
+}
+
+// DeprovisionCluster creates the deprovision job of the SAFCluster, unless it exists.
+func (p *Provisioner) DeprovisionCluster(ctx context.Context, safcl *v1alpha1.SAFCluster) (provisioner.Status, error) {
+	return p.ensureClusterJob(ctx, safcl, provisioner.OperationDeprovisionCluster)
+}
+
+// ClusterStatus reports the status of the job of the operation with the SAFCluster.
+func (p *Provisioner) ClusterStatus(
+	ctx context.Context, safcl *v1alpha1.SAFCluster, op provisioner.Operation,
+) (provisioner.Status, error) {
+	name := clusterJobName(safcl, op)
+	job, err := p.getJobByKey(ctx, types.NamespacedName{Namespace: safcl.Namespace, Name: name})
+	if err != nil || job == nil {
+		return provisioner.Status{State: provisioner.StateNotStarted, Name: name}, err
+	}
+	return p.jobStatus(ctx, job, op)
+}
+
+// CancelCluster deletes the unfinished job of the operation with the SAFCluster together with its pods.
+func (p *Provisioner) CancelCluster(ctx context.Context, safcl *v1alpha1.SAFCluster, op provisioner.Operation) error {
+	job, err := p.getJobByKey(ctx, types.NamespacedName{Namespace: safcl.Namespace, Name: clusterJobName(safcl, op)})
+	if err != nil || job == nil {
+		return err
+	}
+	return p.cancelJob(ctx, job)
+}
+
+func (p *Provisioner) ensureClusterJob(
+	ctx context.Context, safcl *v1alpha1.SAFCluster, op provisioner.Operation,
+) (provisioner.Status, error) {
+	l := logf.FromContext(ctx)
+
+	if status, err := p.ClusterStatus(ctx, safcl, op); err != nil || status.State != provisioner.StateNotStarted {
+		return status, err
+	}
+
+	job, err := p.newClusterJob(ctx, safcl, op)
+	if err != nil {
+		return provisioner.Status{}, err
+	}
+	l.Info("create job", "job_name", job.Name, "operation", op)
+	if err := p.Create(ctx, job); client.IgnoreAlreadyExists(err) != nil {
+		return provisioner.Status{}, fmt.Errorf("create job %s: %w", job.Name, err)
+	}
+	return provisioner.Status{State: provisioner.StateRunning, Name: job.Name, StartTime: metav1.Now()}, nil
+}
+
+// newClusterJob builds a Job owned by the SAFCluster from its job template of the operation.
+// Name of the Cluster and the control plane endpoint, if it is known, are exposed to the job.
+func (p *Provisioner) newClusterJob(
+	ctx context.Context, safcl *v1alpha1.SAFCluster, op provisioner.Operation,
+) (*batchv1.Job, error) {
+	tmpl := safcl.Spec.ProvisionJob
+	if op == provisioner.OperationDeprovisionCluster {
+		tmpl = safcl.Spec.DeprovisionJob
+	}
+	if tmpl == nil {
+		return nil, fmt.Errorf("SAFCluster %s has no job for operation %s", safcl.Name, op)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterJobName(safcl, op),
+			Namespace: safcl.Namespace,
+			Labels: map[string]string{
+				v1alpha1.SAFClusterNameLabel: safcl.Name,
+			},
+		},
+		Spec: *tmpl.Spec.DeepCopy(),
+	}
+
+	if job.Spec.Template.Labels == nil {
+		job.Spec.Template.Labels = map[string]string{}
+	}
+	job.Spec.Template.Labels[v1alpha1.SAFClusterNameLabel] = safcl.Name
+
+	env := []corev1.EnvVar{{Name: "SAF_CLUSTER_NAME", Value: safcl.Labels[capv1beta2.ClusterNameLabel]}}
+	if endpoint := safcl.Spec.ControlPlaneEndpoint; endpoint.IsValid() {
+		env = append(env,
+			corev1.EnvVar{Name: "SAF_CONTROL_PLANE_ENDPOINT_HOST", Value: endpoint.Host},
+			corev1.EnvVar{Name: "SAF_CONTROL_PLANE_ENDPOINT_PORT", Value: strconv.Itoa(int(endpoint.Port))},
+		)
+	}
+	podSpec := &job.Spec.Template.Spec
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Env = append(podSpec.InitContainers[i].Env, env...)
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, env...)
+	}
+
+	if err := p.completeJob(ctx, provisioner.Request{}, safcl, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// completeJob exposes connection config to the job containers, defaults the job and sets its owner.
+func (p *Provisioner) completeJob(ctx context.Context, req provisioner.Request, owner client.Object, job *batchv1.Job) error {
+	podSpec := &job.Spec.Template.Spec
+	env, err := p.jobEnv(ctx, req, job.Namespace)
+	if err != nil {
+		return err
+	}
+	for i := range podSpec.InitContainers {
+		podSpec.InitContainers[i].Env = append(podSpec.InitContainers[i].Env, env...)
+	}
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, env...)
+	}
+
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	if job.Spec.BackoffLimit == nil {
+		job.Spec.BackoffLimit = ptr.To[int32](1)
+	}
+
+	if err := controllerutil.SetControllerReference(owner, job, p.Scheme,
+		controllerutil.WithBlockOwnerDeletion(true)); err != nil {
+		return fmt.Errorf("set controller ref before create: %w", err)
+	}
+	return nil
+}
+
+// connectionConfig is the connection config of an object.
+type connectionConfig struct {
+	config  map[string]string
+	sources []v1alpha1.ConnectionConfigSource
+}
+
+// jobEnv returns env, that is exposed to every job container.
+// Connection config of the SAFMachine overrides connection config of the claimed host.
+// SAFMachine is nil for cleaning jobs of the host.
+// Secrets are read from the namespace of the job, as pods reference Secrets only in their namespace.
+func (p *Provisioner) jobEnv(ctx context.Context, req provisioner.Request, namespace string) ([]corev1.EnvVar, error) {
+	safm := req.SAFMachine
+	configs := []connectionConfig{}
+	if req.Host != nil {
+		configs = append(configs, connectionConfig{req.Host.Spec.ConnectionConfig, req.Host.Spec.ConnectionConfigFrom})
+	}
+	if safm != nil {
+		configs = append(configs, connectionConfig{safm.Spec.ConnectionConfig, safm.Spec.ConnectionConfigFrom})
+	}
+
+	env := map[string]corev1.EnvVar{}
+	for _, cc := range configs {
+		for key, value := range cc.config {
+			name := p.ConnectionConfigEnvPrefix + envName(key)
+			env[name] = corev1.EnvVar{Name: name, Value: value}
+		}
+
+		for _, source := range cc.sources {
+			secretEnv, err := p.connectionConfigSecretEnv(ctx, namespace, source)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range secretEnv {
+				env[e.Name] = e
+			}
+		}
+	}
+
+	result := make([]corev1.EnvVar, 0, len(env)+3)
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		result = append(result, env[name])
+	}
+
+	// go last, so they win over connection config with the same name
+	if req.Host != nil {
+		result = append(result, corev1.EnvVar{Name: "SAF_HOST_NAME", Value: req.Host.Name})
+	}
+	if safm != nil {
+		result = append(result, corev1.EnvVar{Name: "SAF_PROVIDER_ID", Value: safm.Spec.ProviderID})
+	}
+	if req.FailureDomain != nil {
+		result = append(result, corev1.EnvVar{Name: "SAF_FAILURE_DOMAIN", Value: req.FailureDomain.Name})
+	}
+	return result, nil
+}
+
+// connectionConfigSecretEnv references Secret data from env. Secret is read to check the keys exist,
+// its values are never copied. Optional entries, whose Secret or key is missing, are skipped.
+func (p *Provisioner) connectionConfigSecretEnv(
+	ctx context.Context, namespace string, source v1alpha1.ConnectionConfigSource,
+) ([]corev1.EnvVar, error) {
+	secretName, keys, optional := "", []string(nil), false
+	switch {
+	case source.ValueFrom != nil:
+		secretName, keys = source.ValueFrom.SecretKeyRef.Name, []string{source.ValueFrom.SecretKeyRef.Key}
+		optional = ptr.Deref(source.ValueFrom.SecretKeyRef.Optional, false)
+	case source.SecretRef != nil:
+		secretName = source.SecretRef.Name
+	default:
+		return nil, fmt.Errorf("connection config source %q has neither valueFrom nor secretRef", source.Name)
+	}
+
+	secret := &corev1.Secret{}
+	err := p.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secretName}, secret)
+	switch {
+	case apierrors.IsNotFound(err) && optional:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("get connection config secret %s: %w", secretName, err)
+	}
+
+	if source.SecretRef != nil {
+		keys = slices.Sorted(maps.Keys(secret.Data))
+	}
+
+	env := make([]corev1.EnvVar, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; !ok {
+			if optional {
+				continue
+			}
+			return nil, fmt.Errorf("connection config secret %s has no key %s", secretName, key)
+		}
+
+		name := key
+		if source.ValueFrom != nil {
+			name = source.Name
+		}
+		env = append(env, corev1.EnvVar{
+			Name: p.ConnectionConfigEnvPrefix + envName(name),
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+					Key:                  key,
+				},
+			},
+		})
+	}
+
+	return env, nil
+}
+
+// envName turns connection config key into env name, e.g. ssh-user into SSH_USER.
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+func jobName(req provisioner.Request, op provisioner.Operation) string {
+	switch op {
+	case provisioner.OperationDeprovision:
+		return req.SAFMachine.Name + "-deprovision"
+	case provisioner.OperationInspect:
+		return req.SAFMachine.Name + "-inspect"
+	}
+	if req.Attempt <= 1 {
+		return req.SAFMachine.Name + "-provision"
+	}
+	return fmt.Sprintf("%s-provision-%d", req.SAFMachine.Name, req.Attempt)
+}
+
+func clusterJobName(safcl *v1alpha1.SAFCluster, op provisioner.Operation) string {
+	if op == provisioner.OperationDeprovisionCluster {
+		return safcl.Name + "-cluster-deprovision"
+	}
+	return safcl.Name + "-cluster-provision"
+}
+
+// jobFinished returns the terminal condition of the job, if it has one.
+func jobFinished(job *batchv1.Job) (batchv1.JobCondition, bool) {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return c, true
+		}
+	}
+	return batchv1.JobCondition{}, false
+}
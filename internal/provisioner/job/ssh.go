@@ -0,0 +1,155 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package job
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// DefaultSSHImage is the default of Provisioner.SSHImage.
+const DefaultSSHImage = "docker.io/library/alpine:3.22"
+
+const (
+	sshKeyVolumeName = "ssh-key"
+	sshKeyMountPath  = "/etc/saf/ssh/"
+	sshKeyFile       = "id"
+
+	defaultSSHProvisionCommand = "sudo sh -ec '" +
+		"cloud-init clean --logs; " +
+		"for stage in \"init --local\" init \"modules --mode=config\" \"modules --mode=final\"; do " +
+		"cloud-init --file /var/lib/saf/bootstrap-data $stage; " +
+		"done'"
+	defaultSSHDeprovisionCommand = "sudo sh -ec 'kubeadm reset --force; rm -rf /var/lib/saf'"
+)
+
+// sshScript connects to the host with the key and the host key from env,
+// it is the same for provision and deprovision jobs, they differ by SAF_SSH_COMMAND.
+const sshScript = `set -eu
+command -v ssh >/dev/null || apk add --no-cache openssh-client >/dev/null
+
+if [ -n "${SAF_SSH_HOST_KEY:-}" ]; then
+  if [ "$SAF_SSH_PORT" = 22 ]; then known_host="$SAF_SSH_HOST"; else known_host="[$SAF_SSH_HOST]:$SAF_SSH_PORT"; fi
+  echo "$known_host $SAF_SSH_HOST_KEY" > /tmp/known_hosts
+  host_key_opts="-o StrictHostKeyChecking=yes -o UserKnownHostsFile=/tmp/known_hosts"
+else
+  host_key_opts="-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null"
+fi
+
+remote() {
+  ssh -i /etc/saf/ssh/id -p "$SAF_SSH_PORT" -o BatchMode=yes $host_key_opts "$SAF_SSH_USER@$SAF_SSH_HOST" "$@"
+}
+
+if [ "$SAF_SSH_UPLOAD_BOOTSTRAP_DATA" = true ]; then
+  remote 'sudo mkdir -p /var/lib/saf && sudo tee /var/lib/saf/bootstrap-data >/dev/null' < /etc/bootstrap/value
+fi
+remote "$SAF_SSH_COMMAND"
+
+printf '{"hostname":"%s","addresses":%s}' "$(remote hostname)" "$SAF_SSH_ADDRESSES" > /dev/termination-log
+`
+
+// sshJobTemplate generates the job template, that runs the command on the host over ssh.
+// Exit status of the command becomes the exit status of the job.
+func (p *Provisioner) sshJobTemplate(ssh *v1alpha1.SSHProvisioner, provision bool) v1alpha1.JobTemplate {
+	command := ssh.DeprovisionCommand
+	if command == "" {
+		command = defaultSSHDeprovisionCommand
+	}
+	if provision {
+		command = ssh.ProvisionCommand
+		if command == "" {
+			command = defaultSSHProvisionCommand
+		}
+	}
+
+	image := ssh.Image
+	if image == "" {
+		image = p.SSHImage
+	}
+	if image == "" {
+		image = DefaultSSHImage
+	}
+
+	port := ssh.Port
+	if port == 0 {
+		port = 22
+	}
+	user := ssh.User
+	if user == "" {
+		user = "root"
+	}
+
+	return v1alpha1.JobTemplate{
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:    "ssh",
+						Image:   image,
+						Command: []string{"sh", "-c", sshScript},
+						Env: []corev1.EnvVar{
+							{Name: "SAF_SSH_HOST", Value: ssh.Host},
+							{Name: "SAF_SSH_PORT", Value: strconv.Itoa(int(port))},
+							{Name: "SAF_SSH_USER", Value: user},
+							{Name: "SAF_SSH_HOST_KEY", Value: ssh.HostKey},
+							{Name: "SAF_SSH_COMMAND", Value: command},
+							{Name: "SAF_SSH_UPLOAD_BOOTSTRAP_DATA", Value: strconv.FormatBool(provision)},
+							{Name: "SAF_SSH_ADDRESSES", Value: sshAddresses(ssh.Host)},
+						},
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      sshKeyVolumeName,
+							ReadOnly:  true,
+							MountPath: sshKeyMountPath,
+						}},
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+					}},
+					Volumes: []corev1.Volume{{
+						Name: sshKeyVolumeName,
+						VolumeSource: corev1.VolumeSource{
+							Secret: &corev1.SecretVolumeSource{
+								SecretName:  ssh.KeySecretRef.Name,
+								Items:       []corev1.KeyToPath{{Key: ssh.KeySecretRef.Key, Path: sshKeyFile}},
+								DefaultMode: ptr.To[int32](0o400),
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// sshAddresses reports the ssh host as machine address, JSON encoded for provision result.
+func sshAddresses(host string) string {
+	address := capv1beta2.MachineAddress{Type: capv1beta2.MachineInternalDNS, Address: host}
+	if net.ParseIP(host) != nil {
+		address.Type = capv1beta2.MachineInternalIP
+	}
+
+	// marshaling of plain strings doesn't fail
+	data, _ := json.Marshal([]capv1beta2.MachineAddress{address})
+	return string(data)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package noop provisions nothing, every operation succeeds at once.
+// It suits hosts, that are prepared out of band, and tests of the controller.
+package noop
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
+)
+
+// Name is the name of operations, reported in their statuses.
+const Name = "noop"
+
+// Provisioner keeps no state, the status of operation is derived from the SAFMachine.
+type Provisioner struct{}
+
+var _ provisioner.Provisioner = &Provisioner{}
+
+// Provision succeeds at once with empty result.
+func (p *Provisioner) Provision(_ context.Context, _ provisioner.Request) (provisioner.Status, error) {
+	return succeeded(), nil
+}
+
+// Deprovision succeeds at once.
+func (p *Provisioner) Deprovision(_ context.Context, _ provisioner.Request) (provisioner.Status, error) {
+	return succeeded(), nil
+}
+
+// Status reports provisioning succeeded, once the attempt is recorded by the controller,
+// and deprovisioning succeeded, once the SAFMachine is deleting.
+func (p *Provisioner) Status(_ context.Context, req provisioner.Request, op provisioner.Operation) (provisioner.Status, error) {
+	started := false
+	switch op {
+	case provisioner.OperationProvision:
+		for _, attempt := range req.SAFMachine.Status.ProvisionAttempts {
+			started = started || attempt.Attempt == req.Attempt
+		}
+	case provisioner.OperationDeprovision:
+		started = req.SAFMachine.GetDeletionTimestamp() != nil
+	}
+
+	if !started {
+		return provisioner.Status{State: provisioner.StateNotStarted, Name: Name}, nil
+	}
+	return succeeded(), nil
+}
+
+// Cancel does nothing, as operations are never running.
+func (p *Provisioner) Cancel(_ context.Context, _ provisioner.Request, _ provisioner.Operation) error {
+	return nil
+}
+
+func succeeded() provisioner.Status {
+	now := metav1.Now()
+	return provisioner.Status{
+		State:          provisioner.StateSucceeded,
+		Name:           Name,
+		StartTime:      now,
+		CompletionTime: ptr.To(now),
+		Result:         &v1alpha1.ProvisionResult{},
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package provisioner defines backends, that provision and deprovision hosts of SAFMachines.
+// SAFMachine controller keeps track of attempts, retries and timeouts, backends only run operations.
+package provisioner
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// Operation is what is done with the host or the cluster.
+type Operation string
+
+const (
+	// OperationProvision provisions the host, so it joins the workload cluster.
+	OperationProvision Operation = "Provision"
+	// OperationDeprovision cleans the host up, after the SAFMachine is deleted.
+	OperationDeprovision Operation = "Deprovision"
+	// OperationInspect reports facts about the host before provisioning.
+	OperationInspect Operation = "Inspect"
+	// OperationClean wipes the released SAFHost, before it is claimed again.
+	OperationClean Operation = "Clean"
+	// OperationProvisionCluster sets up the infrastructure of the SAFCluster, e.g. its control plane endpoint.
+	OperationProvisionCluster Operation = "ProvisionCluster"
+	// OperationDeprovisionCluster cleans the infrastructure of the SAFCluster up, after it is deleted.
+	OperationDeprovisionCluster Operation = "DeprovisionCluster"
+)
+
+// Request identifies the SAFMachine and the attempt of the operation.
+type Request struct {
+	SAFMachine *v1alpha1.SAFMachine
+	// Machine owns the SAFMachine, it always has bootstrap data for provisioning.
+	// It may be nil for deprovisioning.
+	Machine *capv1beta2.Machine
+	// Host is the SAFHost claimed by the SAFMachine, its connection settings are used,
+	// unless the SAFMachine overrides them. It is nil, if SAFMachine has no hostSelector.
+	Host *v1alpha1.SAFHost
+	// FailureDomain is the failure domain of SAFCluster, the SAFMachine is placed into by its Machine.
+	// It is nil, if the Machine has no failure domain.
+	FailureDomain *v1alpha1.SAFFailureDomain
+	// Attempt is the number of provisioning attempt, starting from 1. It is always 1 for deprovisioning.
+	Attempt int32
+}
+
+// State is the state of the operation.
+type State string
+
+const (
+	// StateNotStarted means the operation is not started yet or is already forgotten by the backend.
+	StateNotStarted State = "NotStarted"
+	// StateRunning means the operation is in progress.
+	StateRunning State = "Running"
+	// StateSucceeded means the operation finished successfully.
+	StateSucceeded State = "Succeeded"
+	// StateFailed means the operation failed.
+	StateFailed State = "Failed"
+)
+
+// Status is the observed status of the operation.
+type Status struct {
+	State State
+	// Name identifies the operation for users, e.g. name of the job.
+	Name string
+	// Message describes the failure of the operation.
+	Message string
+	// StartTime is the time the operation was started.
+	StartTime metav1.Time
+	// CompletionTime is the time the operation finished.
+	CompletionTime *metav1.Time
+	// Canceling is true, when the operation is running, but is being canceled.
+	Canceling bool
+	// Result of succeeded provisioning.
+	Result *v1alpha1.ProvisionResult
+	// Facts about the host, reported by succeeded inspection.
+	Facts *v1alpha1.HostFacts
+	// ClusterResult is the result of succeeded provisioning of the cluster.
+	ClusterResult *v1alpha1.SAFClusterProvisionResult
+	// RequeueAfter asks to check the status again, for backends whose progress doesn't trigger reconciliation.
+	RequeueAfter time.Duration
+}
+
+// Finished reports, if the operation succeeded or failed.
+func (s Status) Finished() bool {
+	return s.State == StateSucceeded || s.State == StateFailed
+}
+
+// Provisioner runs operations with hosts. All methods must be idempotent, as they are called on every reconciliation.
+type Provisioner interface {
+	// Provision starts provisioning attempt and returns its status.
+	Provision(ctx context.Context, req Request) (Status, error)
+	// Deprovision starts deprovisioning and returns its status.
+	Deprovision(ctx context.Context, req Request) (Status, error)
+	// Status returns the status of the operation.
+	Status(ctx context.Context, req Request, op Operation) (Status, error)
+	// Cancel stops the running operation, e.g. after timeout or when the SAFMachine is deleted.
+	Cancel(ctx context.Context, req Request, op Operation) error
+}
+
+// Inspector is implemented by backends, that can inspect hosts before provisioning.
+// Status of inspection is returned by Provisioner.Status with OperationInspect.
+type Inspector interface {
+	// Inspect starts inspection of the host and returns its status.
+	Inspect(ctx context.Context, req Request) (Status, error)
+}
+
+// NodeSimulator is implemented by backends, whose hosts run no kubelet, so no Node joins the workload cluster.
+// SAFMachine controller calls it for provisioned SAFMachines instead of waiting for a real Node.
+type NodeSimulator interface {
+	// SimulateNode creates the Node of the SAFMachine in the workload cluster, or renews its heartbeat,
+	// and returns it. It is called periodically, until the SAFMachine is deleted.
+	SimulateNode(ctx context.Context, c client.Client, req Request) (*corev1.Node, error)
+}
+
+// ClusterProvisioner runs operations with infrastructure of SAFClusters. All methods must be idempotent.
+type ClusterProvisioner interface {
+	// ProvisionCluster starts provisioning of the SAFCluster and returns its status.
+	ProvisionCluster(ctx context.Context, safcl *v1alpha1.SAFCluster) (Status, error)
+	// DeprovisionCluster starts deprovisioning of the deleted SAFCluster and returns its status.
+	DeprovisionCluster(ctx context.Context, safcl *v1alpha1.SAFCluster) (Status, error)
+	// ClusterStatus returns the status of the operation with the SAFCluster.
+	ClusterStatus(ctx context.Context, safcl *v1alpha1.SAFCluster, op Operation) (Status, error)
+	// CancelCluster stops the running operation with the SAFCluster.
+	CancelCluster(ctx context.Context, safcl *v1alpha1.SAFCluster, op Operation) error
+}
+
+// Cleaner cleans released SAFHosts, before they are claimed again.
+type Cleaner interface {
+	// Clean starts cleaning of the host and returns its status.
+	Clean(ctx context.Context, host *v1alpha1.SAFHost) (Status, error)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
+)
+
+var _ provisioner.ClusterProvisioner = &Provisioner{}
+var _ provisioner.Cleaner = &Provisioner{}
+
+// ProvisionCluster starts provisioning at creation of the SAFCluster, it finishes after the delay and never fails,
+// as the SAFCluster doesn't retry provisioning. Control plane endpoint is not reported, it is set in the spec.
+func (p *Provisioner) ProvisionCluster(ctx context.Context, safcl *v1alpha1.SAFCluster) (provisioner.Status, error) {
+	return p.ClusterStatus(ctx, safcl, provisioner.OperationProvisionCluster)
+}
+
+// DeprovisionCluster starts deprovisioning, it finishes after the delay since the SAFCluster is deleted.
+func (p *Provisioner) DeprovisionCluster(ctx context.Context, safcl *v1alpha1.SAFCluster) (provisioner.Status, error) {
+	return p.ClusterStatus(ctx, safcl, provisioner.OperationDeprovisionCluster)
+}
+
+// ClusterStatus reports the operation running, until the delay passes since its start.
+// Provisioning of the deleted SAFCluster is reported as not started, as it is canceled.
+func (p *Provisioner) ClusterStatus(
+	_ context.Context, safcl *v1alpha1.SAFCluster, op provisioner.Operation,
+) (provisioner.Status, error) {
+	var startTime metav1.Time
+	switch op {
+	case provisioner.OperationProvisionCluster:
+		if safcl.GetDeletionTimestamp() == nil {
+			startTime = safcl.GetCreationTimestamp()
+		}
+	case provisioner.OperationDeprovisionCluster:
+		if safcl.GetDeletionTimestamp() != nil {
+			startTime = *safcl.GetDeletionTimestamp()
+		}
+	}
+	if startTime.IsZero() {
+		return provisioner.Status{State: provisioner.StateNotStarted, Name: Name}, nil
+	}
+
+	status := provisioner.Status{
+		State:     provisioner.StateRunning,
+		Name:      Name,
+		StartTime: startTime,
+	}
+	completionTime := startTime.Add(p.Delay)
+	if left := time.Until(completionTime); left > 0 {
+		status.RequeueAfter = left
+		return status, nil
+	}
+	status.State = provisioner.StateSucceeded
+	status.CompletionTime = ptr.To(metav1.NewTime(completionTime))
+	return status, nil
+}
+
+// CancelCluster does nothing, provisioning of the deleted SAFCluster is not reported.
+func (p *Provisioner) CancelCluster(_ context.Context, _ *v1alpha1.SAFCluster, _ provisioner.Operation) error {
+	return nil
+}
+
+// Clean succeeds at once, as SAFHost keeps no time of the cleaning start to derive its progress from.
+func (p *Provisioner) Clean(_ context.Context, _ *v1alpha1.SAFHost) (provisioner.Status, error) {
+	now := metav1.Now()
+	return provisioner.Status{
+		State:          provisioner.StateSucceeded,
+		Name:           Name,
+		StartTime:      now,
+		CompletionTime: &now,
+	}, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
+)
+
+// NodeTaint keeps pods off simulated Nodes, as no kubelet runs them.
+const NodeTaint = "infrastructure.cluster.x-k8s.io/simulated"
+
+var _ provisioner.NodeSimulator = &Provisioner{}
+
+// SimulateNode creates the Node with provider ID of the SAFMachine, so Cluster API sets Machine's nodeRef,
+// and renews heartbeat of its Ready condition, so the Node is not marked unreachable.
+// Status of the Ready condition is kept, so MachineHealthCheck remediation is tested by setting it to False.
+func (p *Provisioner) SimulateNode(ctx context.Context, c client.Client, req provisioner.Request) (*corev1.Node, error) {
+	safm := req.SAFMachine
+
+	node := &corev1.Node{}
+	if err := c.Get(ctx, client.ObjectKey{Name: safm.Name}, node); apierrors.IsNotFound(err) {
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   safm.Name,
+				Labels: map[string]string{corev1.LabelHostname: safm.Name},
+			},
+			Spec: corev1.NodeSpec{
+				ProviderID: safm.Spec.ProviderID,
+				Taints: []corev1.Taint{{
+					Key:    NodeTaint,
+					Effect: corev1.TaintEffectNoSchedule,
+				}},
+			},
+		}
+		if err := c.Create(ctx, node); err != nil {
+			return nil, fmt.Errorf("create node: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.Spec.ProviderID != safm.Spec.ProviderID {
+		return nil, fmt.Errorf("node %s has provider id %q of another machine", node.Name, node.Spec.ProviderID)
+	}
+
+	nodePatch := client.MergeFrom(node.DeepCopy())
+	now := metav1.Now()
+	renewed := false
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == corev1.NodeReady {
+			node.Status.Conditions[i].LastHeartbeatTime = now
+			renewed = true
+		}
+	}
+	if !renewed {
+		node.Status.Conditions = append(node.Status.Conditions, corev1.NodeCondition{
+			Type:               corev1.NodeReady,
+			Status:             corev1.ConditionTrue,
+			Reason:             "SimulatedNodeReady",
+			Message:            "Node is simulated, no kubelet runs on it",
+			LastHeartbeatTime:  now,
+			LastTransitionTime: now,
+		})
+	}
+	if len(node.Status.Addresses) == 0 {
+		node.Status.Addresses = []corev1.NodeAddress{
+			{Type: corev1.NodeHostName, Address: safm.Name},
+			{Type: corev1.NodeInternalIP, Address: fakeIP(safm)},
+		}
+	}
+	if err := c.Status().Patch(ctx, node, nodePatch); err != nil {
+		return nil, fmt.Errorf("renew heartbeat of node %s: %w", node.Name, err)
+	}
+	return node, nil
+}
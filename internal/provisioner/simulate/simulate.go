@@ -0,0 +1,155 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulate pretends to provision hosts and clusters and to clean hosts, so Cluster API flows,
+// like MachineDeployment rollouts and MachineHealthCheck remediation, can be tested without real hosts.
+// No pods are run, Nodes of provisioned hosts are faked in the workload cluster.
+package simulate
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/provisioner"
+)
+
+// DefaultDelay is the default of Provisioner.Delay.
+const DefaultDelay = 10 * time.Second
+
+// Name is the name of operations, reported in their statuses.
+const Name = "simulated"
+
+// Provisioner keeps no state, operations are started at the time recorded on the SAFMachine,
+// and outcome of every attempt is derived from SAFMachine's uid, so it is the same on every reconciliation.
+type Provisioner struct {
+	// Delay is the default duration of operations.
+	Delay time.Duration
+
+	// FailurePercent is the default chance of a provisioning attempt to fail, in percent.
+	FailurePercent int32
+}
+
+var _ provisioner.Provisioner = &Provisioner{}
+
+// Provision starts the attempt, it finishes after the delay.
+func (p *Provisioner) Provision(_ context.Context, req provisioner.Request) (provisioner.Status, error) {
+	return p.status(req, provisioner.OperationProvision, metav1.Now()), nil
+}
+
+// Deprovision starts deprovisioning, it finishes after the delay since the SAFMachine is deleted.
+func (p *Provisioner) Deprovision(_ context.Context, req provisioner.Request) (provisioner.Status, error) {
+	return p.status(req, provisioner.OperationDeprovision, metav1.Now()), nil
+}
+
+// Status reports the operation running, until the delay passes since its start.
+func (p *Provisioner) Status(_ context.Context, req provisioner.Request, op provisioner.Operation) (provisioner.Status, error) {
+	return p.status(req, op, metav1.Time{}), nil
+}
+
+// Cancel does nothing, the controller stops observing canceled attempts.
+func (p *Provisioner) Cancel(_ context.Context, _ provisioner.Request, _ provisioner.Operation) error {
+	return nil
+}
+
+// status derives the status of the operation. Operations, that are not recorded on the SAFMachine, start at now,
+// or are not started, if now is zero.
+func (p *Provisioner) status(req provisioner.Request, op provisioner.Operation, now metav1.Time) provisioner.Status {
+	safm := req.SAFMachine
+	startTime := now
+	switch op {
+	case provisioner.OperationProvision:
+		for _, attempt := range safm.Status.ProvisionAttempts {
+			if attempt.Attempt == req.Attempt {
+				startTime = attempt.StartTime
+			}
+		}
+	case provisioner.OperationDeprovision:
+		if safm.GetDeletionTimestamp() != nil {
+			startTime = *safm.GetDeletionTimestamp()
+		}
+	}
+	if startTime.IsZero() {
+		return provisioner.Status{State: provisioner.StateNotStarted, Name: Name}
+	}
+
+	status := provisioner.Status{
+		State:     provisioner.StateRunning,
+		Name:      Name,
+		StartTime: startTime,
+	}
+	completionTime := startTime.Add(p.delay(safm))
+	if left := time.Until(completionTime); left > 0 {
+		status.RequeueAfter = left
+		return status
+	}
+	status.CompletionTime = ptr.To(metav1.NewTime(completionTime))
+
+	if op == provisioner.OperationProvision && p.fails(req) {
+		status.State = provisioner.StateFailed
+		status.Message = fmt.Sprintf("Simulated failure of attempt %d", req.Attempt)
+		return status
+	}
+
+	status.State = provisioner.StateSucceeded
+	if op == provisioner.OperationProvision {
+		status.Result = &v1alpha1.ProvisionResult{
+			Hostname: safm.Name,
+			Addresses: []capv1beta2.MachineAddress{{
+				Type:    capv1beta2.MachineInternalIP,
+				Address: fakeIP(safm),
+			}},
+		}
+	}
+	return status
+}
+
+func (p *Provisioner) delay(safm *v1alpha1.SAFMachine) time.Duration {
+	if safm.Spec.Simulate != nil && safm.Spec.Simulate.Delay != nil {
+		return safm.Spec.Simulate.Delay.Duration
+	}
+	return p.Delay
+}
+
+// fails decides the outcome of the attempt by the hash of SAFMachine's uid and the attempt number.
+func (p *Provisioner) fails(req provisioner.Request) bool {
+	failurePercent := p.FailurePercent
+	if req.SAFMachine.Spec.Simulate != nil && req.SAFMachine.Spec.Simulate.FailurePercent != nil {
+		failurePercent = *req.SAFMachine.Spec.Simulate.FailurePercent
+	}
+
+	h := fnv.New32a()
+	_, _ = fmt.Fprintf(h, "%s/%d", req.SAFMachine.UID, req.Attempt)
+	return int32(h.Sum32()%100) < failurePercent
+}
+
+// fakeIP returns an address from 10.0.0.0/8, derived from SAFMachine's uid.
+func fakeIP(safm *v1alpha1.SAFMachine) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(safm.UID))
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, h.Sum32())
+	ip[0] = 10
+	return ip.String()
+}
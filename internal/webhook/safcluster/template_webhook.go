@@ -0,0 +1,89 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safcluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api/util/topology"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+const safClusterTemplateImmutableMsg = "SAFClusterTemplate spec.template.spec field is immutable. " +
+	"Please create a new resource instead."
+
+// TemplateWebhook validates SAFClusterTemplate objects.
+type TemplateWebhook struct{}
+
+var _ webhook.CustomValidator = &TemplateWebhook{}
+
+// SetupWithManager sets up the webhook with the Manager.
+func (w *TemplateWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.SAFClusterTemplate{}).
+		WithValidator(w).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1alpha1-safclustertemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=safclustertemplates,verbs=create;update,versions=v1alpha1,name=validation.safclustertemplate.infrastructure.cluster.x-k8s.io,admissionReviewVersions=v1
+
+// ValidateCreate implements webhook.CustomValidator.
+func (w *TemplateWebhook) ValidateCreate(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateUpdate rejects changes of the cluster spec, metadata of the template is still mutable.
+func (w *TemplateWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldTmpl, ok := oldObj.(*v1alpha1.SAFClusterTemplate)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a SAFClusterTemplate but got a %T", oldObj))
+	}
+	newTmpl, ok := newObj.(*v1alpha1.SAFClusterTemplate)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a SAFClusterTemplate but got a %T", newObj))
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a admission.Request inside context: %v", err))
+	}
+
+	// topology controller checks templates with dry-run requests
+	if topology.IsDryRunRequest(req, newTmpl) ||
+		reflect.DeepEqual(newTmpl.Spec.Template.Spec, oldTmpl.Spec.Template.Spec) {
+		return nil, nil
+	}
+
+	return nil, apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind(v1alpha1.SAFClusterTemplateKind).GroupKind(),
+		newTmpl.Name, field.ErrorList{
+			field.Forbidden(field.NewPath("spec", "template", "spec"), safClusterTemplateImmutableMsg),
+		})
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (w *TemplateWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safcluster_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+var _ = Describe("SAFClusterTemplate Webhook", func() {
+	Context("When updating SAFClusterTemplate", func() {
+		var tmpl *v1alpha1.SAFClusterTemplate
+
+		BeforeEach(func() {
+			tmpl = &v1alpha1.SAFClusterTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template-update",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.SAFClusterTemplateSpec{
+					Template: v1alpha1.SAFClusterTemplateResource{
+						Spec: v1alpha1.SAFClusterSpec{
+							ControlPlaneEndpoint: capv1beta2.APIEndpoint{Host: "10.0.0.10", Port: 6443},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tmpl)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, tmpl)).To(Succeed())
+			})
+		})
+
+		It("should deny spec changes", func() {
+			tmpl.Spec.Template.Spec.ControlPlaneEndpoint.Port = 8443
+
+			err := k8sClient.Update(ctx, tmpl)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec"))
+		})
+
+		It("should allow metadata changes", func() {
+			tmpl.Labels = map[string]string{"foo": "bar"}
+			tmpl.Spec.Template.ObjectMeta = capv1beta2.ObjectMeta{
+				Labels: map[string]string{capv1beta2.ClusterTopologyOwnedLabel: ""},
+			}
+
+			Expect(k8sClient.Update(ctx, tmpl)).To(Succeed())
+		})
+	})
+})
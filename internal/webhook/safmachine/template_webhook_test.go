@@ -0,0 +1,94 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachine_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capv1beta2 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+var _ = Describe("SAFMachineTemplate Webhook", func() {
+	newSAFMachineTemplate := func(name string) *v1alpha1.SAFMachineTemplate {
+		return &v1alpha1.SAFMachineTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1alpha1.SAFMachineTemplateSpec{
+				Template: v1alpha1.SAFMachineTemplateResource{
+					Spec: v1alpha1.SAFMachineSpec{
+						ProvisionJob:   jobTemplate(),
+						DeprovisionJob: jobTemplate(),
+					},
+				},
+			},
+		}
+	}
+
+	Context("When creating SAFMachineTemplate", func() {
+		It("should deny job without containers", func() {
+			tmpl := newSAFMachineTemplate("test-template-no-containers")
+			tmpl.Spec.Template.Spec.ProvisionJob.Spec.Template.Spec.Containers = []corev1.Container{}
+
+			err := k8sClient.Create(ctx, tmpl)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+		})
+	})
+
+	Context("When updating SAFMachineTemplate", func() {
+		var tmpl *v1alpha1.SAFMachineTemplate
+
+		BeforeEach(func() {
+			tmpl = newSAFMachineTemplate("test-template-update")
+			Expect(k8sClient.Create(ctx, tmpl)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, tmpl)).To(Succeed())
+			})
+		})
+
+		It("should deny spec changes", func() {
+			tmpl.Spec.Template.Spec.ProvisionJob.Spec.Template.Spec.Containers[0].Image = "other"
+
+			err := k8sClient.Update(ctx, tmpl)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.template.spec"))
+		})
+
+		It("should allow metadata changes", func() {
+			tmpl.Labels = map[string]string{"foo": "bar"}
+			tmpl.Spec.Template.ObjectMeta = capv1beta2.ObjectMeta{
+				Labels: map[string]string{capv1beta2.ClusterTopologyOwnedLabel: ""},
+			}
+
+			Expect(k8sClient.Update(ctx, tmpl)).To(Succeed())
+		})
+
+		It("should allow spec changes in topology dry-run", func() {
+			tmpl.Annotations = map[string]string{capv1beta2.TopologyDryRunAnnotation: ""}
+			tmpl.Spec.Template.Spec.ProvisionJob.Spec.Template.Spec.Containers[0].Image = "other"
+
+			Expect(k8sClient.Update(ctx, tmpl, client.DryRunAll)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,285 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachine
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+)
+
+// defaultTTLSecondsAfterFinished keeps finished jobs long enough to read their results and debug failures.
+const defaultTTLSecondsAfterFinished = int32(24 * 60 * 60)
+
+// Webhook defaults and validates SAFMachine objects.
+type Webhook struct{}
+
+var (
+	_ webhook.CustomDefaulter = &Webhook{}
+	_ webhook.CustomValidator = &Webhook{}
+)
+
+// SetupWithManager sets up the webhook with the Manager.
+func (w *Webhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.SAFMachine{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-infrastructure-cluster-x-k8s-io-v1alpha1-safmachine,mutating=true,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=safmachines,verbs=create;update,versions=v1alpha1,name=default.safmachine.infrastructure.cluster.x-k8s.io,admissionReviewVersions=v1
+
+// Default fills job templates with the values, controller would use anyway, so they are visible on the object.
+func (w *Webhook) Default(_ context.Context, obj runtime.Object) error {
+	safm, ok := obj.(*v1alpha1.SAFMachine)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a SAFMachine but got a %T", obj))
+	}
+
+	defaultSpec(&safm.Spec)
+	return nil
+}
+
+func defaultSpec(spec *v1alpha1.SAFMachineSpec) {
+	if spec.Provisioner == "" {
+		spec.Provisioner = v1alpha1.JobProvisioner
+	}
+	if spec.InspectionJob != nil {
+		// failed inspection is retried on deletion of its job, so ttl would retry it silently
+		defaultRestartPolicy(spec.InspectionJob)
+	}
+	// jobs of ssh provisioner are generated by controller
+	if spec.Provisioner != v1alpha1.JobProvisioner || spec.SSH != nil || usesHostSSH(spec) {
+		return
+	}
+	defaultJobTemplate(&spec.ProvisionJob)
+	defaultJobTemplate(&spec.DeprovisionJob)
+}
+
+func defaultJobTemplate(tmpl *v1alpha1.JobTemplate) {
+	defaultRestartPolicy(tmpl)
+	if tmpl.Spec.TTLSecondsAfterFinished == nil {
+		tmpl.Spec.TTLSecondsAfterFinished = ptr.To(defaultTTLSecondsAfterFinished)
+	}
+}
+
+func defaultRestartPolicy(tmpl *v1alpha1.JobTemplate) {
+	if tmpl.Spec.Template.Spec.RestartPolicy == "" {
+		tmpl.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-infrastructure-cluster-x-k8s-io-v1alpha1-safmachine,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.cluster.x-k8s.io,resources=safmachines,verbs=create;update,versions=v1alpha1,name=validation.safmachine.infrastructure.cluster.x-k8s.io,admissionReviewVersions=v1
+
+// ValidateCreate implements webhook.CustomValidator.
+func (w *Webhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	safm, ok := obj.(*v1alpha1.SAFMachine)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a SAFMachine but got a %T", obj))
+	}
+
+	return nil, w.validate(safm)
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+func (w *Webhook) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldSAFM, ok := oldObj.(*v1alpha1.SAFMachine)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a SAFMachine but got a %T", oldObj))
+	}
+	safm, ok := newObj.(*v1alpha1.SAFMachine)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a SAFMachine but got a %T", newObj))
+	}
+
+	// provisioning attempts are tracked by the backend, that started them
+	if safm.Spec.Provisioner != oldSAFM.Spec.Provisioner {
+		return nil, apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind(v1alpha1.SAFMachineKind).GroupKind(), safm.Name,
+			field.ErrorList{field.Forbidden(field.NewPath("spec", "provisioner"), "field is immutable")})
+	}
+	// finalizers and metadata of the object, created before validation was tightened, must stay updatable
+	if safm.GetDeletionTimestamp() != nil || equality.Semantic.DeepEqual(safm.Spec, oldSAFM.Spec) {
+		return nil, nil
+	}
+
+	return nil, w.validate(safm)
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (w *Webhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *Webhook) validate(safm *v1alpha1.SAFMachine) error {
+	allErrs := validateSpec(&safm.Spec, field.NewPath("spec"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind(v1alpha1.SAFMachineKind).GroupKind(), safm.Name, allErrs)
+}
+
+func validateSpec(spec *v1alpha1.SAFMachineSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch {
+	case spec.Provisioner != "" && spec.Provisioner != v1alpha1.JobProvisioner:
+		// other backends don't run jobs
+		if spec.SSH != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("ssh"),
+				fmt.Sprintf("ssh is supported by %s provisioner only", v1alpha1.JobProvisioner)))
+		}
+		if spec.InspectionJob != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("inspectionJob"),
+				fmt.Sprintf("inspection is supported by %s provisioner only", v1alpha1.JobProvisioner)))
+		}
+	case spec.SSH != nil:
+		allErrs = append(allErrs, validateSSH(spec, fldPath)...)
+	case usesHostSSH(spec):
+		// ssh of the claimed host is checked by controller
+	default:
+		allErrs = append(allErrs, validateJobTemplate(&spec.ProvisionJob, fldPath.Child("provisionJob"))...)
+		allErrs = append(allErrs, validateJobTemplate(&spec.DeprovisionJob, fldPath.Child("deprovisionJob"))...)
+	}
+	if spec.InspectionJob != nil {
+		allErrs = append(allErrs, validateJobTemplate(spec.InspectionJob, fldPath.Child("inspectionJob"))...)
+	} else if spec.HostRequirements != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("hostRequirements"),
+			"host requirements are checked against facts, reported by inspectionJob"))
+	}
+	if spec.HostSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(spec.HostSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("hostSelector"), spec.HostSelector, err.Error()))
+		}
+	}
+	allErrs = append(allErrs, validateConnectionConfigFrom(spec.ConnectionConfigFrom, fldPath.Child("connectionConfigFrom"))...)
+	return allErrs
+}
+
+// usesHostSSH reports, if SAFMachine has no job templates and relies on ssh of the host, claimed by hostSelector.
+func usesHostSSH(spec *v1alpha1.SAFMachineSpec) bool {
+	return spec.HostSelector != nil && reflect.DeepEqual(spec.ProvisionJob, v1alpha1.JobTemplate{}) &&
+		reflect.DeepEqual(spec.DeprovisionJob, v1alpha1.JobTemplate{})
+}
+
+func validateConnectionConfigFrom(sources []v1alpha1.ConnectionConfigSource, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, source := range sources {
+		sourcePath := fldPath.Index(i)
+		switch {
+		case source.ValueFrom != nil && source.SecretRef != nil:
+			allErrs = append(allErrs, field.Forbidden(sourcePath, "only one of valueFrom and secretRef may be set"))
+		case source.ValueFrom != nil:
+			if source.Name == "" {
+				allErrs = append(allErrs, field.Required(sourcePath.Child("name"), "name is required with valueFrom"))
+			}
+			if source.ValueFrom.SecretKeyRef.Name == "" {
+				allErrs = append(allErrs, field.Required(sourcePath.Child("valueFrom", "secretKeyRef", "name"), ""))
+			}
+		case source.SecretRef != nil:
+			if source.Name != "" {
+				allErrs = append(allErrs, field.Forbidden(sourcePath.Child("name"), "name must not be set with secretRef"))
+			}
+			if source.SecretRef.Name == "" {
+				allErrs = append(allErrs, field.Required(sourcePath.Child("secretRef", "name"), ""))
+			}
+		default:
+			allErrs = append(allErrs, field.Required(sourcePath, "one of valueFrom and secretRef must be set"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateSSH(spec *v1alpha1.SAFMachineSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !reflect.DeepEqual(spec.ProvisionJob, v1alpha1.JobTemplate{}) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("provisionJob"), "must not be set with ssh"))
+	}
+	if !reflect.DeepEqual(spec.DeprovisionJob, v1alpha1.JobTemplate{}) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("deprovisionJob"), "must not be set with ssh"))
+	}
+	if spec.SSH.KeySecretRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("ssh", "keySecretRef", "name"), ""))
+	}
+
+	return allErrs
+}
+
+func validateJobTemplate(tmpl *v1alpha1.JobTemplate, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	podSpecPath := fldPath.Child("spec", "template", "spec")
+	podSpec := &tmpl.Spec.Template.Spec
+
+	if len(podSpec.Containers) == 0 {
+		allErrs = append(allErrs, field.Required(podSpecPath.Child("containers"), "job must have at least one container"))
+	}
+
+	// controller adds the bootstrap volume and mounts it to every container
+	for i, volume := range podSpec.Volumes {
+		if volume.Name == v1alpha1.BootstrapVolumeName {
+			allErrs = append(allErrs, field.Invalid(podSpecPath.Child("volumes").Index(i).Child("name"), volume.Name,
+				"volume name is reserved for bootstrap data"))
+		}
+	}
+	for i, container := range podSpec.InitContainers {
+		allErrs = append(allErrs, validateVolumeMounts(container.VolumeMounts,
+			podSpecPath.Child("initContainers").Index(i).Child("volumeMounts"))...)
+	}
+	for i, container := range podSpec.Containers {
+		allErrs = append(allErrs, validateVolumeMounts(container.VolumeMounts,
+			podSpecPath.Child("containers").Index(i).Child("volumeMounts"))...)
+	}
+
+	return allErrs
+}
+
+func validateVolumeMounts(mounts []corev1.VolumeMount, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	bootstrapPath := path.Clean(v1alpha1.BootstrapMountPath)
+
+	for i, mount := range mounts {
+		if mount.Name == v1alpha1.BootstrapVolumeName {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("name"), mount.Name,
+				"volume name is reserved for bootstrap data"))
+		}
+		// the bootstrap mount path itself and everything under it
+		if strings.HasPrefix(path.Clean(mount.MountPath)+"/", bootstrapPath+"/") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("mountPath"), mount.MountPath,
+				fmt.Sprintf("mount path clashes with bootstrap data mounted at %s", v1alpha1.BootstrapMountPath)))
+		}
+	}
+
+	return allErrs
+}
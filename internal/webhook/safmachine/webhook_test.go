@@ -0,0 +1,287 @@
+/*
+Copyright 2025 GoodCoffeeLover.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package safmachine_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/GoodCoffeeLover/saf-api/api/v1alpha1"
+	"github.com/GoodCoffeeLover/saf-api/internal/webhook/safmachine"
+)
+
+var _ = Describe("SAFMachine Webhook", func() {
+	newSAFMachine := func(name string) *v1alpha1.SAFMachine {
+		return &v1alpha1.SAFMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: v1alpha1.SAFMachineSpec{
+				ProvisionJob:   jobTemplate(),
+				DeprovisionJob: jobTemplate(),
+			},
+		}
+	}
+
+	Context("When creating SAFMachine", func() {
+		It("should default job templates", func() {
+			safm := newSAFMachine("test-defaulting")
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(safm), safm)).To(Succeed())
+			for _, tmpl := range []v1alpha1.JobTemplate{safm.Spec.ProvisionJob, safm.Spec.DeprovisionJob} {
+				Expect(tmpl.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+				Expect(tmpl.Spec.TTLSecondsAfterFinished).NotTo(BeNil())
+			}
+		})
+
+		It("should keep restart policy set by user", func() {
+			safm := newSAFMachine("test-restart-policy")
+			safm.Spec.ProvisionJob.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			Expect(safm.Spec.ProvisionJob.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyOnFailure))
+		})
+
+		It("should deny job without containers", func() {
+			safm := newSAFMachine("test-no-containers")
+			safm.Spec.DeprovisionJob.Spec.Template.Spec.Containers = []corev1.Container{}
+
+			err := k8sClient.Create(ctx, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.deprovisionJob.spec.template.spec.containers"))
+		})
+
+		It("should deny volume clashing with bootstrap volume", func() {
+			safm := newSAFMachine("test-bootstrap-volume")
+			safm.Spec.ProvisionJob.Spec.Template.Spec.Volumes = []corev1.Volume{{
+				Name:         v1alpha1.BootstrapVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}}
+
+			err := k8sClient.Create(ctx, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.provisionJob.spec.template.spec.volumes[0].name"))
+		})
+
+		It("should deny mount under bootstrap mount path", func() {
+			safm := newSAFMachine("test-bootstrap-mount")
+			safm.Spec.ProvisionJob.Spec.Template.Spec.Volumes = []corev1.Volume{{
+				Name:         "scripts",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}}
+			safm.Spec.ProvisionJob.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{
+				Name:      "scripts",
+				MountPath: "/etc/bootstrap/scripts",
+			}}
+
+			err := k8sClient.Create(ctx, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.provisionJob.spec.template.spec.containers[0].volumeMounts[0].mountPath"))
+		})
+
+		It("should deny connection config source without secret", func() {
+			safm := newSAFMachine("test-connection-config-from")
+			safm.Spec.ConnectionConfigFrom = []v1alpha1.ConnectionConfigSource{{Name: "password"}}
+
+			err := k8sClient.Create(ctx, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.connectionConfigFrom[0]"))
+		})
+
+		It("should not default job templates of ssh provisioner", func() {
+			safm := newSAFMachine("test-ssh")
+			safm.Spec.ProvisionJob = v1alpha1.JobTemplate{}
+			safm.Spec.DeprovisionJob = v1alpha1.JobTemplate{}
+			safm.Spec.SSH = &v1alpha1.SSHProvisioner{
+				Host: "10.0.0.1",
+				KeySecretRef: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "ssh"},
+					Key:                  "ssh-privatekey",
+				},
+			}
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			Expect(safm.Spec.ProvisionJob).To(Equal(v1alpha1.JobTemplate{}))
+		})
+
+		It("should deny job templates with ssh provisioner", func() {
+			safm := newSAFMachine("test-ssh-with-jobs")
+			safm.Spec.SSH = &v1alpha1.SSHProvisioner{
+				Host: "10.0.0.1",
+				KeySecretRef: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "ssh"},
+					Key:                  "ssh-privatekey",
+				},
+			}
+
+			err := k8sClient.Create(ctx, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.provisionJob"))
+		})
+
+		It("should allow host selector without job templates", func() {
+			safm := newSAFMachine("test-host-selector")
+			safm.Spec.HostSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "ssh"}}
+			safm.Spec.ProvisionJob = v1alpha1.JobTemplate{}
+			safm.Spec.DeprovisionJob = v1alpha1.JobTemplate{}
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			Expect(safm.Spec.ProvisionJob).To(Equal(v1alpha1.JobTemplate{}))
+		})
+
+		It("should allow noop provisioner without job templates", func() {
+			safm := newSAFMachine("test-noop")
+			safm.Spec.Provisioner = v1alpha1.NoopProvisioner
+			safm.Spec.ProvisionJob = v1alpha1.JobTemplate{}
+			safm.Spec.DeprovisionJob = v1alpha1.JobTemplate{}
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			Expect(safm.Spec.ProvisionJob).To(Equal(v1alpha1.JobTemplate{}))
+		})
+
+		It("should default inspection job template", func() {
+			safm := newSAFMachine("test-inspection")
+			safm.Spec.InspectionJob = ptr.To(jobTemplate())
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			Expect(safm.Spec.InspectionJob.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			Expect(safm.Spec.InspectionJob.Spec.TTLSecondsAfterFinished).To(BeNil())
+		})
+
+		It("should deny host requirements without inspection job", func() {
+			safm := newSAFMachine("test-requirements")
+			safm.Spec.HostRequirements = &v1alpha1.HostRequirements{Architecture: "amd64"}
+
+			err := k8sClient.Create(ctx, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.hostRequirements"))
+		})
+
+		It("should deny inspection job with noop provisioner", func() {
+			safm := newSAFMachine("test-noop-inspection")
+			safm.Spec.Provisioner = v1alpha1.NoopProvisioner
+			safm.Spec.InspectionJob = ptr.To(jobTemplate())
+
+			err := k8sClient.Create(ctx, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.inspectionJob"))
+		})
+	})
+
+	Context("When updating SAFMachine", func() {
+		It("should deny removing all containers", func() {
+			safm := newSAFMachine("test-update")
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+
+			safm.Spec.ProvisionJob.Spec.Template.Spec.Containers = []corev1.Container{}
+			err := k8sClient.Update(ctx, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+		})
+
+		It("should deny changing provisioner", func() {
+			safm := newSAFMachine("test-update-provisioner")
+			Expect(k8sClient.Create(ctx, safm)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, safm)).To(Succeed())
+			})
+			Expect(safm.Spec.Provisioner).To(Equal(v1alpha1.JobProvisioner))
+
+			safm.Spec.Provisioner = v1alpha1.NoopProvisioner
+			err := k8sClient.Update(ctx, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+			Expect(err.Error()).To(ContainSubstring("spec.provisioner"))
+		})
+
+		It("should allow updating metadata of SAFMachine with invalid spec", func() {
+			// e.g. created before validation was tightened
+			oldSAFM := newSAFMachine("test-update-invalid")
+			oldSAFM.Spec.ProvisionJob.Spec.Template.Spec.Containers = nil
+			safm := oldSAFM.DeepCopy()
+			safm.Finalizers = []string{v1alpha1.SAFMachineFinalizer}
+
+			_, err := (&safmachine.Webhook{}).ValidateUpdate(ctx, oldSAFM, safm)
+			Expect(err).NotTo(HaveOccurred())
+
+			safm.Spec.DeprovisionJob.Spec.Template.Spec.Containers = nil
+			_, err = (&safmachine.Webhook{}).ValidateUpdate(ctx, oldSAFM, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+		})
+
+		It("should allow updating deleted SAFMachine with invalid spec", func() {
+			oldSAFM := newSAFMachine("test-update-deleted")
+			oldSAFM.Spec.ProvisionJob.Spec.Template.Spec.Containers = nil
+			oldSAFM.DeletionTimestamp = ptr.To(metav1.Now())
+			safm := oldSAFM.DeepCopy()
+			safm.Finalizers = nil
+			safm.Spec.DeprovisionJob.Spec.Template.Spec.Containers = nil
+
+			_, err := (&safmachine.Webhook{}).ValidateUpdate(ctx, oldSAFM, safm)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("keeping provisioner immutable")
+			safm.Spec.Provisioner = v1alpha1.NoopProvisioner
+			_, err = (&safmachine.Webhook{}).ValidateUpdate(ctx, oldSAFM, safm)
+			Expect(errors.IsInvalid(err)).To(BeTrue(), "unexpected error: %v", err)
+		})
+	})
+})
+
+func jobTemplate() v1alpha1.JobTemplate {
+	return v1alpha1.JobTemplate{
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "main",
+							Image: "main",
+						},
+					},
+				},
+			},
+		},
+	}
+}